@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// groupByDay splits chronologically sorted events into runs that start on
+// the same local day.
+func groupByDay(items []*calendar.Event) [][]*calendar.Event {
+	var groups [][]*calendar.Event
+	var current time.Time
+	for _, item := range items {
+		day := startOfDay(eventStart(item).Local())
+		if len(groups) == 0 || !day.Equal(current) {
+			groups = append(groups, nil)
+			current = day
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], item)
+	}
+	return groups
+}
+
+// agendaWindow resolves the agenda flags. --to wins over --days.
+func agendaWindow(fromValue, toValue string, days int, now time.Time) (time.Time, time.Time, error) {
+	from, err := parseDate(fromValue, now)
+	if err != nil {
+		return from, from, fmt.Errorf("--from: %w", err)
+	}
+	if toValue == "" {
+		if days < 1 {
+			return from, from, fmt.Errorf("--days must be at least 1")
+		}
+		return from, startOfDay(from).AddDate(0, 0, days), nil
+	}
+	to, err := parseDate(toValue, now)
+	if err != nil {
+		return from, to, fmt.Errorf("--to: %w", err)
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("--to must be after --from")
+	}
+	return from, to, nil
+}
+
+// runAgenda lists every event in the range selected by --from and --to or
+// --days as one table per day. The range starts today by default. Events
+// outside working hours are dimmed or left out as --out-of-hours says.
+func runAgenda(ctx context.Context, srv *calendar.Service, w io.Writer) error {
+	fromValue := *fromFlag
+	if fromValue == "" {
+		fromValue = "today"
+	}
+
+	now := time.Now()
+	from, to, err := agendaWindow(fromValue, *toFlag, agendaDays, now)
+	if err != nil {
+		return err
+	}
+	events, err := fetchEvents(ctx, srv, from, to)
+	if err != nil {
+		return err
+	}
+	warnStale(os.Stderr)
+	events.Items = filterEvents(events.Items)
+	loadColorColumn(ctx, srv)
+	// --working-hours was validated with the other global flags.
+	hours, _ := configuredHours()
+	if ok, err := writeEvents(w, events.Items); ok {
+		return err
+	}
+	if len(events.Items) == 0 {
+		fmt.Fprintln(w, "No events found.")
+		return nil
+	}
+
+	for _, group := range groupByDay(events.Items) {
+		var rows [][]string
+		dimmed := map[int]bool{}
+		for i, item := range group {
+			rows = append(rows, eventRow(item, now))
+			dimmed[i] = *outOfHours == "dim" && hours.outside(item)
+		}
+		fmt.Fprintln(w, CaptionStyle.Render(eventStart(group[0]).Local().Format("Monday, Jan 2")))
+		fmt.Fprintln(w, renderTable(rows, group, dimmed))
+	}
+	return nil
+}
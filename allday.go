@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// allDayModes are the values --all-day accepts.
+var allDayModes = []string{"show", "hide", "only"}
+
+func validAllDay(mode string) error {
+	for _, m := range allDayModes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown mode %q; use one of %s", mode, strings.Join(allDayModes, ", "))
+}
+
+// isAllDay reports whether item spans whole days, given as dates rather
+// than times.
+func isAllDay(item *calendar.Event) bool {
+	return item.Start != nil && item.Start.Date != ""
+}
+
+// filterAllDay applies --all-day: hide drops all-day events, only keeps
+// nothing else.
+func filterAllDay(items []*calendar.Event) []*calendar.Event {
+	if *allDayFlag == "show" {
+		return items
+	}
+	var kept []*calendar.Event
+	for _, item := range items {
+		if isAllDay(item) == (*allDayFlag == "only") {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
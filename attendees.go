@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The attendees subcommands' flags.
+var (
+	attendeesOptional bool
+	attendeesNotify   bool
+)
+
+// changeAttendees returns current without the guests in remove and with
+// those in add, matching addresses case-insensitively. With optional the
+// added guests are optional, including those already invited, who keep their
+// answer. changed counts the guests that were added, removed or changed.
+func changeAttendees(current []*calendar.EventAttendee, add, remove []string, optional bool) (attendees []*calendar.EventAttendee, changed int) {
+	drop := map[string]bool{}
+	for _, email := range remove {
+		drop[strings.ToLower(email)] = true
+	}
+	attendees = []*calendar.EventAttendee{}
+	seen := map[string]*calendar.EventAttendee{}
+	for _, a := range current {
+		if drop[strings.ToLower(a.Email)] {
+			changed++
+			continue
+		}
+		a := *a
+		attendees = append(attendees, &a)
+		seen[strings.ToLower(a.Email)] = &a
+	}
+	for _, email := range add {
+		if a := seen[strings.ToLower(email)]; a != nil {
+			if optional && !a.Optional {
+				a.Optional = true
+				changed++
+			}
+			continue
+		}
+		a := &calendar.EventAttendee{Email: email, Optional: optional}
+		attendees = append(attendees, a)
+		seen[strings.ToLower(email)] = a
+		changed++
+	}
+	return attendees, changed
+}
+
+// checkEmails rejects arguments that cannot be email addresses.
+func checkEmails(emails []string) {
+	for _, email := range emails {
+		if !isEmail(email) {
+			usageFatalf("%q is not an email address", email)
+		}
+	}
+}
+
+// writeAttendees prints item's guests and their answers, or with --output
+// json the API's attendee list.
+func writeAttendees(w io.Writer, item *calendar.Event) error {
+	if *outputFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(item.Attendees)
+	}
+	if len(item.Attendees) == 0 {
+		_, err := fmt.Fprintln(w, "No guests.")
+		return err
+	}
+	for _, a := range item.Attendees {
+		if _, err := fmt.Fprintln(w, guestLine(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateAttendees applies the add and remove lists to the event given by
+// arg and reports the result.
+func updateAttendees(arg string, add, remove []string) {
+	requireScopes(writeScope)
+	requireContacts(add)
+	srv := newService()
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	calendarID, item, err := findEvent(ctx, srv, arg)
+	if err != nil {
+		fatal("Unable to find event", err)
+	}
+	if add, err = resolveAttendees(ctx, add); err != nil {
+		fatal("Unable to find guest", err)
+	}
+	for _, email := range remove {
+		if !hasAttendee(item, email) {
+			log.Printf("%s is not invited to %s", email, item.Summary)
+		}
+	}
+	attendees, changed := changeAttendees(item.Attendees, add, remove, attendeesOptional)
+	if changed == 0 {
+		fmt.Println("Nothing to change.")
+		return
+	}
+	patch := &calendar.Event{Attendees: attendees, ForceSendFields: []string{"Attendees"}}
+	updated, err := events.Patch(ctx, srv, calendarID, item.Id, patch, attendeesNotify, callOptions()...)
+	if err != nil {
+		fatal("Unable to update guests", scopeError(err))
+	}
+	fmt.Printf("Updated the guests of %s  %s\n", eventWhen(updated), updated.Summary)
+	if err := writeAttendees(os.Stdout, updated); err != nil {
+		fatal("Unable to list guests", err)
+	}
+}
+
+// hasAttendee reports whether email is on item's guest list.
+func hasAttendee(item *calendar.Event, email string) bool {
+	for _, a := range item.Attendees {
+		if strings.EqualFold(a.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+var attendeesCmd = &cobra.Command{
+	Use:   "attendees",
+	Short: "List, invite or uninvite an event's guests",
+	Long: "Manage the guest list of an event, given by ID or by title as for show. For an occurrence of a " +
+		"recurring event only that occurrence changes; give the series' ID to change all of them.",
+}
+
+var attendeesListCmd = &cobra.Command{
+	Use:   "list EVENT",
+	Short: "List an event's guests and their answers",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		_, item, err := findEvent(ctx, srv, args[0])
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		if err := writeAttendees(os.Stdout, item); err != nil {
+			fatal("Unable to list guests", err)
+		}
+	},
+}
+
+var attendeesAddCmd = &cobra.Command{
+	Use:   "add EVENT GUEST...",
+	Short: "Invite guests to an event",
+	Long: "Invite guests to an event. --optional marks them as optional, including guests already invited.\n\n" +
+		"A guest can be given by name, which is looked up in your contacts and your domain's directory, asking " +
+		"which one is meant when several match. The first lookup asks for read access to your contacts.",
+	Example: `  go-gcal-cli attendees add abc123 a@example.com b@example.com
+  go-gcal-cli attendees add "design review" sam --optional --notify=false`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateAttendees(args[0], args[1:], nil)
+	},
+}
+
+var attendeesRemoveCmd = &cobra.Command{
+	Use:   "remove EVENT EMAIL...",
+	Short: "Uninvite guests from an event",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkEmails(args[1:])
+		updateAttendees(args[0], nil, args[1:])
+	},
+}
+
+func init() {
+	attendeesAddCmd.Flags().BoolVar(&attendeesOptional, "optional", false, "invite the guests as optional")
+	for _, c := range []*cobra.Command{attendeesAddCmd, attendeesRemoveCmd} {
+		c.Flags().BoolVar(&attendeesNotify, "notify", true, "email the guests about the change")
+	}
+	attendeesCmd.AddCommand(attendeesListCmd, attendeesAddCmd, attendeesRemoveCmd)
+	rootCmd.AddCommand(attendeesCmd)
+}
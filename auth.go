@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+const revokeURL = "https://oauth2.googleapis.com/revoke"
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the saved OAuth token",
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Run the OAuth flow and save a fresh token",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config := oauthConfig()
+		store, err := openTokenStore()
+		if err != nil {
+			fatal("Unable to open token store", err)
+		}
+		saveToken(store, authorize(config))
+		fmt.Println("Authorized.")
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the authorized account, scopes and token expiry",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openTokenStore()
+		if err != nil {
+			fatal("Unable to open token store", err)
+		}
+		tok, err := store.Load()
+		if err != nil {
+			fmt.Printf("Not logged in (profile %s): %v\n", activeProfile(), err)
+			return
+		}
+
+		fmt.Printf("Profile:  %s\n", activeProfile())
+		fmt.Printf("Storage:  %s\n", store)
+		fmt.Printf("Scopes:   %s\n", strings.Join(tok.Scopes, " "))
+		if tok.Expiry.IsZero() {
+			fmt.Println("Expires:  never")
+		} else {
+			fmt.Printf("Expires:  %s (%s)\n", tok.Expiry.Local().Format("2006-01-02 15:04"), time.Until(tok.Expiry).Round(time.Minute))
+		}
+		fmt.Printf("Refresh:  %t\n", tok.RefreshToken != "")
+
+		// The primary calendar's ID is the account's email address, which
+		// spares us from requesting a userinfo scope.
+		client := oauthConfig().Client(context.Background(), &tok.Token)
+		srv, err := calendar.NewService(context.Background(), option.WithHTTPClient(client))
+		if err != nil {
+			fatal("Unable to retrieve Calendar client", err)
+		}
+		ctx, cancel := requestContext()
+		defer cancel()
+		primary, err := srv.CalendarList.Get("primary").Context(ctx).Do(callOptions()...)
+		if err != nil {
+			fmt.Printf("Account:  unknown (%v)\n", err)
+			return
+		}
+		fmt.Printf("Account:  %s\n", primary.Id)
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Delete the locally saved token",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openTokenStore()
+		if err != nil {
+			fatal("Unable to open token store", err)
+		}
+		if err := store.Delete(); err != nil {
+			fatal("Unable to delete token", err)
+		}
+		fmt.Printf("Logged out of profile %s.\n", activeProfile())
+	},
+}
+
+var authRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke the token with Google and delete it locally",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openTokenStore()
+		if err != nil {
+			fatal("Unable to open token store", err)
+		}
+		tok, err := store.Load()
+		if err != nil {
+			fatal("Unable to load token", err)
+		}
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := revokeToken(ctx, tok); err != nil {
+			fatal("Unable to revoke token", err)
+		}
+		if err := store.Delete(); err != nil {
+			fatal("Unable to delete token", err)
+		}
+		fmt.Println("Token revoked.")
+	},
+}
+
+// revokeToken invalidates the token with Google. Revoking the refresh token
+// also revokes every access token issued from it.
+func revokeToken(ctx context.Context, tok *storedToken) error {
+	value := tok.RefreshToken
+	if value == "" {
+		value = tok.AccessToken
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocation endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func init() {
+	authCmd.AddCommand(authLoginCmd, authStatusCmd, authLogoutCmd, authRevokeCmd)
+	rootCmd.AddCommand(authCmd)
+}
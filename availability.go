@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// writeAvailability prints free slots one day per line, as in
+// "Tue Oct 14: 10:00–10:30, 14:00–15:30 CET", for pasting into a message.
+func writeAvailability(w io.Writer, slots []period) {
+	var day time.Time
+	var times []string
+	flush := func() {
+		if len(times) > 0 {
+			zone, _ := day.Zone()
+			fmt.Fprintf(w, "%s: %s %s\n", day.Format("Mon Jan 2"), strings.Join(times, ", "), zone)
+		}
+	}
+	for _, p := range slots {
+		start, end := p.Start.Local(), p.End.Local()
+		if d := startOfDay(start); !d.Equal(day) {
+			flush()
+			day, times = d, nil
+		}
+		times = append(times, start.Format("15:04")+"–"+end.Format("15:04"))
+	}
+	flush()
+}
+
+// The availability command's flags.
+var (
+	availabilityDays     int
+	availabilityDuration time.Duration
+	availabilityWeekends bool
+)
+
+var availabilityCmd = &cobra.Command{
+	Use:   "availability",
+	Short: "Print your free time as text to paste into an email or chat",
+	Long: "List the gaps of at least --duration in --working-hours on --working-days over the next --days days, " +
+		"starting today, one day per line. Busy time comes from the selected calendars' free/busy, so events marked " +
+		"free do not count.",
+	Example: `  go-gcal-cli availability --days 5 --duration 30m
+  go-gcal-cli availability --working-hours 10:00-16:00 --tz America/New_York`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		now := time.Now()
+		hours, err := configuredHours()
+		if err != nil {
+			usageFatalf("Invalid working hours: %v", err)
+		}
+		if availabilityWeekends {
+			hours.days[time.Saturday], hours.days[time.Sunday] = true, true
+		}
+		if availabilityDays < 1 {
+			usageFatalf("--days must be at least 1")
+		}
+		if availabilityDuration <= 0 {
+			usageFatalf("--duration must be positive")
+		}
+		end := startOfDay(now).AddDate(0, 0, availabilityDays)
+
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		result, err := queryFreeBusy(ctx, srv, calendarIDs(), now, end)
+		if err != nil {
+			fatal("Unable to query free/busy", err)
+		}
+		var busy []period
+		for _, b := range result {
+			if b.Err != "" {
+				log.Fatalf("Unable to read free/busy of %s: %s", b.ID, b.Err)
+			}
+			busy = append(busy, b.Busy...)
+		}
+
+		slots := freeSlots(hours.windows(now, end), busy, availabilityDuration)
+		if len(slots) == 0 {
+			fmt.Println("No free time found.")
+			return
+		}
+		writeAvailability(os.Stdout, slots)
+	},
+}
+
+func init() {
+	f := availabilityCmd.Flags()
+	f.IntVar(&availabilityDays, "days", 5, "number of days to cover, starting today")
+	f.DurationVar(&availabilityDuration, "duration", 30*time.Minute, "shortest gap worth offering")
+	f.BoolVar(&availabilityWeekends, "weekends", false, "include Saturdays and Sundays")
+	rootCmd.AddCommand(availabilityCmd)
+}
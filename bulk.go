@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// bulkFields are the columns of a create --from-file CSV, or the keys of its
+// JSON objects.
+var bulkFields = []string{"title", "start", "end", "duration", "all_day", "location", "description", "attendees", "meet", "repeat", "time_zone"}
+
+// bulkRow is one event of a --from-file batch. Where names it in messages.
+type bulkRow struct {
+	Where  string
+	Fields map[string]string
+}
+
+// readBulkFile reads the events of path, or of stdin for "-": a JSON array
+// of objects, or CSV with a header row, tab-separated for .tsv files.
+func readBulkFile(path string) ([]bulkRow, error) {
+	r := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	br := bufio.NewReader(r)
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" || ext == "" && startsWithBracket(br) {
+		return readBulkJSON(br)
+	}
+	comma := ','
+	if ext == ".tsv" {
+		comma = '\t'
+	}
+	return readBulkCSV(br, comma)
+}
+
+// startsWithBracket peeks past leading white space for a JSON array.
+func startsWithBracket(br *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		b, err := br.Peek(n)
+		if err != nil {
+			return false
+		}
+		switch b[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b[n-1] == '['
+	}
+}
+
+func readBulkCSV(r io.Reader, comma rune) ([]bulkRow, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no header row")
+	}
+	header := make([]string, len(records[0]))
+	for i, name := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if err := checkBulkField(header[i]); err != nil {
+			return nil, err
+		}
+	}
+	var rows []bulkRow
+	for i, record := range records[1:] {
+		row := bulkRow{Where: fmt.Sprintf("line %d", i+2), Fields: map[string]string{}}
+		for j, value := range record {
+			if j < len(header) {
+				row.Fields[header[j]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readBulkJSON takes values of any JSON type; lists are joined as a CSV
+// attendees cell would be.
+func readBulkJSON(r io.Reader) ([]bulkRow, error) {
+	var objects []map[string]any
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, err
+	}
+	var rows []bulkRow
+	for i, object := range objects {
+		row := bulkRow{Where: fmt.Sprintf("event %d", i+1), Fields: map[string]string{}}
+		for key, value := range object {
+			key = strings.ToLower(key)
+			if err := checkBulkField(key); err != nil {
+				return nil, fmt.Errorf("%s: %w", row.Where, err)
+			}
+			switch v := value.(type) {
+			case nil:
+			case string:
+				row.Fields[key] = strings.TrimSpace(v)
+			case []any:
+				var parts []string
+				for _, p := range v {
+					parts = append(parts, fmt.Sprint(p))
+				}
+				row.Fields[key] = strings.Join(parts, ";")
+			default:
+				row.Fields[key] = fmt.Sprint(v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// splitAttendees splits an attendees cell at semicolons or commas.
+func splitAttendees(cell string) []string {
+	var attendees []string
+	for _, a := range strings.FieldsFunc(cell, func(r rune) bool { return r == ';' || r == ',' }) {
+		if a = strings.TrimSpace(a); a != "" {
+			attendees = append(attendees, a)
+		}
+	}
+	return attendees
+}
+
+func checkBulkField(name string) error {
+	if !slices.Contains(bulkFields, name) {
+		return fmt.Errorf("unknown column %q; use %s", name, strings.Join(bulkFields, ", "))
+	}
+	return nil
+}
+
+// bulkSpec builds the event of row as create would from the matching flags.
+// Events without a time zone get zone.
+func bulkSpec(row bulkRow, now time.Time, zone string) (events.Spec, error) {
+	f := row.Fields
+	spec := events.Spec{
+		Title:       f["title"],
+		Duration:    30 * time.Minute,
+		TimeZone:    cmp.Or(f["time_zone"], zone),
+		Location:    f["location"],
+		Description: f["description"],
+	}
+	var err error
+	for _, b := range []struct {
+		key string
+		to  *bool
+	}{{"all_day", &spec.AllDay}, {"meet", &spec.Meet}} {
+		if f[b.key] != "" {
+			if *b.to, err = strconv.ParseBool(f[b.key]); err != nil {
+				return spec, fmt.Errorf("%s: %q is not true or false", b.key, f[b.key])
+			}
+		}
+	}
+	if f["start"] == "" {
+		return spec, fmt.Errorf("start is required")
+	}
+	if spec.Start, err = parseDate(f["start"], now); err != nil {
+		return spec, fmt.Errorf("start: %w", err)
+	}
+	switch {
+	case f["end"] != "":
+		end, err := parseDate(f["end"], now)
+		if err != nil {
+			return spec, fmt.Errorf("end: %w", err)
+		}
+		if !end.After(spec.Start) {
+			return spec, fmt.Errorf("end must be after start")
+		}
+		spec.Duration = end.Sub(spec.Start)
+	case f["duration"] != "":
+		if spec.Duration, err = time.ParseDuration(f["duration"]); err != nil {
+			return spec, fmt.Errorf("duration: %w", err)
+		}
+	case spec.AllDay:
+		spec.Duration = 24 * time.Hour
+	}
+	spec.Attendees = splitAttendees(f["attendees"])
+	if f["repeat"] != "" {
+		rule, err := parseRepeat(f["repeat"], now, spec.AllDay)
+		if err != nil {
+			return spec, fmt.Errorf("repeat: %w", err)
+		}
+		spec.Recurrence = []string{rule}
+	}
+	return spec, nil
+}
+
+// runBulk creates the events of rows on the first selected calendar,
+// reporting each one and going on past rows that fail. It returns how many
+// failed.
+func runBulk(ctx context.Context, srv *calendar.Service, rows []bulkRow, w io.Writer, dryRun bool) (int, error) {
+	id := calendarIDs()[0]
+	zone := *tzFlag
+	if zone == "" {
+		// Recurring events need a zone; the calendar's is what the web UI
+		// uses.
+		cal, err := srv.Calendars.Get(id).Fields("timeZone").Context(ctx).Do(callOptions()...)
+		if err != nil {
+			return 0, err
+		}
+		zone = cal.TimeZone
+	}
+	now := time.Now()
+	failed := 0
+	for i, row := range rows {
+		progress := fmt.Sprintf("[%d/%d]", i+1, len(rows))
+		item, err := bulkEvent(ctx, row, now, zone)
+		if err == nil && !dryRun {
+			item, err = events.Insert(ctx, srv, id, item, callOptions()...)
+			err = scopeError(err)
+		}
+		switch {
+		case err != nil:
+			failed++
+			log.Printf("%s %s: %v", progress, row.Where, err)
+		case dryRun:
+			fmt.Fprintf(w, "%s Would create %s  %s\n", progress, eventWhen(item), item.Summary)
+		default:
+			fmt.Fprintf(w, "%s Created %s  %s (%s)\n", progress, eventWhen(item), item.Summary, item.Id)
+		}
+	}
+	if dryRun {
+		fmt.Fprintf(w, "%d of %d events would be created on %s.\n", len(rows)-failed, len(rows), calendarName(id))
+	} else {
+		fmt.Fprintf(w, "Created %d of %d events on %s.\n", len(rows)-failed, len(rows), calendarName(id))
+	}
+	return failed, nil
+}
+
+// bulkEvent turns row into the event to insert, looking up guests given by
+// name.
+func bulkEvent(ctx context.Context, row bulkRow, now time.Time, zone string) (*calendar.Event, error) {
+	spec, err := bulkSpec(row, now, zone)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Attendees, err = resolveAttendees(ctx, spec.Attendees); err != nil {
+		return nil, err
+	}
+	return spec.Event()
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// eventCacheDays is how many days before today the event cache reaches back.
+// Windows starting earlier are fetched from the API.
+const eventCacheDays = 30
+
+// eventCache holds one calendar's expanded events from From onwards, kept
+// current through the API's sync tokens: the first sync lists everything,
+// later ones only what changed since SyncToken was issued.
+type eventCache struct {
+	From      time.Time                  `json:"from"`
+	Synced    time.Time                  `json:"synced"`
+	SyncToken string                     `json:"sync_token"`
+	Events    map[string]*calendar.Event `json:"events"`
+}
+
+// eventCacheDir holds the active profile's event caches, one file per
+// calendar.
+func eventCacheDir() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileFileName("events", "", activeProfile())), nil
+}
+
+func eventCachePath(id string) (string, error) {
+	dir, err := eventCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, url.PathEscape(id)+".json"), nil
+}
+
+// eventCacheHorizon is the earliest time the cache covers as of now.
+func eventCacheHorizon(now time.Time) time.Time {
+	return startOfDay(now).AddDate(0, 0, -eventCacheDays)
+}
+
+// useEventCache reports whether a window starting at from can be served from
+// the cache. Series masters are not cached, only their instances.
+func useEventCache(from time.Time) bool {
+	return !*noCache && !*noSingle && !from.Before(eventCacheHorizon(time.Now()))
+}
+
+// loadEventCache reads the cache at path. A missing or unreadable one is
+// empty, which means a full sync.
+func loadEventCache(path string) *eventCache {
+	cache := &eventCache{}
+	if b, err := os.ReadFile(path); err != nil || json.Unmarshal(b, cache) != nil {
+		return &eventCache{}
+	}
+	return cache
+}
+
+// save writes the cache through a temporary file, so that a concurrent
+// reader such as the daemon never sees half of it.
+func (c *eventCache) save(path string) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".events-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// sync applies the changes since the last sync, or lists every event from
+// c.From when there is no sync token yet.
+func (c *eventCache) sync(ctx context.Context, srv *calendar.Service, id string) error {
+	call := srv.Events.List(id).SingleEvents(true).MaxResults(2500).Context(ctx)
+	if c.SyncToken != "" {
+		call = call.SyncToken(c.SyncToken)
+	} else {
+		call = call.TimeMin(c.From.Format(time.RFC3339))
+		c.Events = nil
+	}
+	if c.Events == nil {
+		c.Events = map[string]*calendar.Event{}
+	}
+	for {
+		page, err := call.Do(callOptions()...)
+		if err != nil {
+			return err
+		}
+		for _, item := range page.Items {
+			if item.Status == "cancelled" {
+				delete(c.Events, item.Id)
+			} else {
+				c.Events[item.Id] = item
+			}
+		}
+		if page.NextPageToken == "" {
+			c.SyncToken, c.Synced = page.NextSyncToken, time.Now()
+			return nil
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+}
+
+// prune drops the events that ended before horizon and moves c.From up to
+// it, so that the cache does not grow with the past.
+func (c *eventCache) prune(horizon time.Time) {
+	if !horizon.After(c.From) {
+		return
+	}
+	for key, item := range c.Events {
+		if end := eventEnd(item); !end.IsZero() && end.Before(horizon) {
+			delete(c.Events, key)
+		}
+	}
+	c.From = horizon
+}
+
+// between returns the cached events overlapping [from, to), as the API's
+// timeMin and timeMax select them. A zero to leaves the window open.
+func (c *eventCache) between(from, to time.Time) []*calendar.Event {
+	var items []*calendar.Event
+	for _, item := range c.Events {
+		if eventEnd(item).After(from) && (to.IsZero() || eventStart(item).Before(to)) {
+			items = append(items, item)
+		}
+	}
+	// Map order is random; settle ties so that output is stable.
+	slices.SortFunc(items, func(a, b *calendar.Event) int {
+		return cmp.Or(eventStart(a).Compare(eventStart(b)), cmp.Compare(a.Id, b.Id))
+	})
+	return items
+}
+
+// isNetworkError reports whether err means the API could not be reached, as
+// opposed to an answer from it or from the token endpoint.
+func isNetworkError(err error) bool {
+	var nerr net.Error
+	var rerr *oauth2.RetrieveError
+	return errors.As(err, &nerr) && !errors.As(err, &rerr)
+}
+
+// staleAsOf is when the oldest cache shown without syncing was last synced;
+// zero while everything shown is current.
+var staleAsOf struct {
+	sync.Mutex
+	t time.Time
+}
+
+func markStale(synced time.Time) {
+	staleAsOf.Lock()
+	defer staleAsOf.Unlock()
+	if staleAsOf.t.IsZero() || synced.Before(staleAsOf.t) {
+		staleAsOf.t = synced
+	}
+}
+
+// warnStale tells the user when cached events stood in for the API.
+func warnStale(w io.Writer) {
+	staleAsOf.Lock()
+	defer staleAsOf.Unlock()
+	if !staleAsOf.t.IsZero() {
+		fmt.Fprintf(w, "Offline: stale as of %s\n", staleAsOf.t.Local().Format("Mon Jan 2 15:04"))
+	}
+}
+
+// syncEvents brings the cache of calendar id up to date and returns it. An
+// expired sync token starts over with a full sync. With --offline, or when
+// the API cannot be reached, the cache is returned as last synced.
+func syncEvents(ctx context.Context, srv *calendar.Service, id string) (*eventCache, error) {
+	path, err := eventCachePath(id)
+	if err != nil {
+		return nil, err
+	}
+	if *offline {
+		cache := loadEventCache(path)
+		if cache.SyncToken == "" {
+			return nil, fmt.Errorf("no cached events for %s; run once without --offline", id)
+		}
+		markStale(cache.Synced)
+		return cache, nil
+	}
+	horizon := eventCacheHorizon(time.Now())
+	cache := loadEventCache(path)
+	if cache.SyncToken == "" || cache.From.After(horizon) {
+		cache = &eventCache{From: horizon}
+	}
+	err = cache.sync(ctx, srv, id)
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) && gerr.Code == http.StatusGone {
+		cache = &eventCache{From: horizon}
+		err = cache.sync(ctx, srv, id)
+	}
+	if err != nil {
+		// A failed sync may have applied some changes; fall back to the
+		// file as written.
+		if stale := loadEventCache(path); stale.SyncToken != "" && isNetworkError(err) {
+			markStale(stale.Synced)
+			return stale, nil
+		}
+		return nil, err
+	}
+	cache.prune(horizon)
+	// A failed write only costs a full sync next time.
+	_ = cache.save(path)
+	return cache, nil
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local event cache",
+	Long: fmt.Sprintf("Events from %d days ago onwards are cached per calendar and kept current by fetching only what "+
+		"changed since the last call. --no-cache bypasses the cache for one call.\n\n"+
+		"When the API cannot be reached, or with --offline, the cached events are shown as last synced with a "+
+		"\"stale as of\" note.", eventCacheDays),
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the active profile's cached events",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := eventCacheDir()
+		if err != nil {
+			fatal("Unable to locate the cache", err)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			fatal("Unable to clear the cache", err)
+		}
+		fmt.Println("Cleared the event cache.")
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// calendarList is the user's calendar list, fetched by loadCalendars when
+// events come from more than one calendar.
+var calendarList []*calendar.CalendarListEntry
+
+// multiCalendar reports whether events are listed from several calendars,
+// so that rows need labelling by calendar.
+func multiCalendar() bool {
+	return *allCalendars || len(calendarIDs()) > 1
+}
+
+// loadCalendars fetches the calendar list once, for --all-calendars and for
+// the names and colors that label rows by calendar.
+func loadCalendars(ctx context.Context, srv *calendar.Service) error {
+	if calendarList != nil || !multiCalendar() {
+		return nil
+	}
+	// Refreshing also keeps the cached list current for --offline.
+	entries, _, err := cachedCalendars(ctx, srv, true)
+	if err != nil {
+		return err
+	}
+	calendarList = entries
+	return nil
+}
+
+// listCalendars pages through the user's calendar list.
+func listCalendars(ctx context.Context, srv *calendar.Service) ([]*calendar.CalendarListEntry, error) {
+	call := srv.CalendarList.List().Context(ctx)
+	entries := []*calendar.CalendarListEntry{}
+	for {
+		page, err := call.Do(callOptions()...)
+		if err != nil {
+			return nil, scopeError(err)
+		}
+		entries = append(entries, page.Items...)
+		if page.NextPageToken == "" {
+			return entries, nil
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+}
+
+// calendarEntry looks up id, which may be the primary alias, in the
+// calendar list.
+func calendarEntry(id string) *calendar.CalendarListEntry {
+	for _, entry := range calendarList {
+		if entry.Id == id || id == "primary" && entry.Primary {
+			return entry
+		}
+	}
+	return nil
+}
+
+// calendarName returns the name the user sees for calendar id.
+func calendarName(id string) string {
+	entry := calendarEntry(id)
+	if entry == nil {
+		return id
+	}
+	return entryName(entry)
+}
+
+// entryName is the calendar's name as the user renamed it, else its own.
+func entryName(entry *calendar.CalendarListEntry) string {
+	if entry.SummaryOverride != "" {
+		return entry.SummaryOverride
+	}
+	return entry.Summary
+}
+
+// calendarCacheTTL is how long the cached calendar list is trusted for
+// resolving names. A name that does not resolve refreshes it early.
+const calendarCacheTTL = 24 * time.Hour
+
+// calendarCache is the calendar list as last fetched for name resolution.
+type calendarCache struct {
+	Fetched   time.Time                     `json:"fetched"`
+	Calendars []*calendar.CalendarListEntry `json:"calendars"`
+}
+
+func calendarCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileFileName("calendars", ".json", activeProfile())), nil
+}
+
+// cachedCalendars returns the calendar list from the cache while it is
+// fresh, fetching and caching it otherwise or with refresh set. fetched
+// reports whether the list came from the API. With --offline, or when the
+// API cannot be reached, any cached list will do.
+func cachedCalendars(ctx context.Context, srv *calendar.Service, refresh bool) (entries []*calendar.CalendarListEntry, fetched bool, err error) {
+	path, err := calendarCachePath()
+	if err != nil {
+		return nil, false, err
+	}
+	cache := &calendarCache{}
+	b, err := os.ReadFile(path)
+	cached := err == nil && json.Unmarshal(b, cache) == nil
+	if cached && (*offline || !refresh && time.Since(cache.Fetched) < calendarCacheTTL) {
+		return cache.Calendars, false, nil
+	}
+	if *offline {
+		return nil, false, errors.New("the calendar list is not cached; run once without --offline")
+	}
+	entries, err = listCalendars(ctx, srv)
+	if cached && isNetworkError(err) {
+		return cache.Calendars, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	b, err = json.Marshal(&calendarCache{Fetched: time.Now(), Calendars: entries})
+	if err == nil && os.MkdirAll(filepath.Dir(path), 0700) == nil {
+		// A failed write only costs a fetch next time.
+		_ = os.WriteFile(path, b, 0600)
+	}
+	return entries, true, nil
+}
+
+// isCalendarID tells IDs, which are primary or look like email addresses,
+// from names to resolve.
+func isCalendarID(value string) bool {
+	return value == "primary" || strings.Contains(value, "@")
+}
+
+// errNoCalendar is returned for a name that matches no calendar.
+var errNoCalendar = errors.New("no calendar matches")
+
+// resolveCalendar turns a calendar name, or part of one, into its ID. IDs are
+// returned as they are. The closest match wins as in matchScore; several
+// equally close matches are an error listing them.
+func resolveCalendar(entries []*calendar.CalendarListEntry, value string) (string, error) {
+	if isCalendarID(value) {
+		return value, nil
+	}
+	best := 0
+	var matches []*calendar.CalendarListEntry
+	for _, entry := range entries {
+		score := matchScore(entryName(entry), value)
+		switch {
+		case score == 0:
+		case score > best:
+			best, matches = score, []*calendar.CalendarListEntry{entry}
+		case score == best:
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w %q; run \"calendars\" to list them", errNoCalendar, value)
+	case 1:
+		return matches[0].Id, nil
+	}
+	var names []string
+	for _, entry := range matches {
+		names = append(names, fmt.Sprintf("%s (%s)", entryName(entry), entry.Id))
+	}
+	return "", fmt.Errorf("%q matches several calendars: %s; give more of the name or the ID", value, strings.Join(names, ", "))
+}
+
+// resolveCalendarNames replaces calendar names given to --calendar, in the
+// config file or as the profile's default with their IDs.
+func resolveCalendarNames(ctx context.Context, srv *calendar.Service) error {
+	ids := calendarIDs()
+	if *allCalendars || !slices.ContainsFunc(ids, func(id string) bool { return !isCalendarID(id) }) {
+		return nil
+	}
+	for _, refresh := range []bool{false, true} {
+		entries, fetched, err := cachedCalendars(ctx, srv, refresh)
+		if err != nil {
+			return err
+		}
+		resolved := make([]string, len(ids))
+		for i, id := range ids {
+			if resolved[i], err = resolveCalendar(entries, id); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			calendarFlags = resolved
+			return nil
+		}
+		// Only a name missing from a cached list is worth a refetch.
+		if fetched || *offline || !errors.Is(err, errNoCalendar) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Calendar is the JSON form of a calendar list entry.
+type Calendar struct {
+	ID         string `json:"id"`
+	Summary    string `json:"summary"`
+	AccessRole string `json:"access_role"`
+	TimeZone   string `json:"time_zone,omitempty"`
+	Color      string `json:"color,omitempty"`
+	Primary    bool   `json:"primary,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty"`
+}
+
+func newCalendar(entry *calendar.CalendarListEntry) Calendar {
+	return Calendar{
+		ID:         entry.Id,
+		Summary:    calendarName(entry.Id),
+		AccessRole: entry.AccessRole,
+		TimeZone:   entry.TimeZone,
+		Color:      entry.BackgroundColor,
+		Primary:    entry.Primary,
+		Hidden:     entry.Hidden,
+	}
+}
+
+// colorSwatch renders a block in the calendar's color next to its hex code.
+func colorSwatch(color string) string {
+	if color == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("■") + " " + color
+}
+
+// writeCalendars lists entries one per line, marking the primary calendar,
+// or as JSON with --output json.
+func writeCalendars(w io.Writer, entries []*calendar.CalendarListEntry) error {
+	if *outputFormat == "json" {
+		list := []Calendar{}
+		for _, entry := range entries {
+			list = append(list, newCalendar(entry))
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !*noHeader {
+		fmt.Fprintln(tw, "  ID\tSUMMARY\tACCESS\tTIME ZONE\tCOLOR")
+	}
+	for _, entry := range entries {
+		marker := " "
+		if entry.Primary {
+			marker = "*"
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\n", marker, entry.Id, calendarName(entry.Id), entry.AccessRole, entry.TimeZone, colorSwatch(entry.BackgroundColor))
+	}
+	return tw.Flush()
+}
+
+// writeCalendar prints everything about one calendar list entry.
+func writeCalendar(w io.Writer, entry *calendar.CalendarListEntry) error {
+	if *outputFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(newCalendar(entry))
+	}
+	var reminders string
+	if len(entry.DefaultReminders) > 0 {
+		reminders = reminderList(entry.DefaultReminders)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, field := range [][2]string{
+		{"ID", entry.Id},
+		{"Summary", calendarName(entry.Id)},
+		{"Description", entry.Description},
+		{"Access", entry.AccessRole},
+		{"Time zone", entry.TimeZone},
+		{"Color", colorSwatch(entry.BackgroundColor)},
+		{"Primary", fmt.Sprint(entry.Primary)},
+		{"Shown", fmt.Sprint(entry.Selected && !entry.Hidden)},
+		{"Reminders", reminders},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+		}
+	}
+	return tw.Flush()
+}
+
+var calendarsCmd = &cobra.Command{
+	Use:   "calendars [CALENDAR]",
+	Short: "List your calendars, or show one, to find IDs for --calendar",
+	Long: "List every calendar in your calendar list with its ID, name, your access role, time zone and color. " +
+		"The primary calendar is marked with *.\n\n" +
+		"Given a calendar ID or name, print its details instead. --output json prints either as JSON.\n\n" +
+		"Wherever a calendar is expected, as with --calendar, a name or part of one works too: " +
+		"\"--calendar team\" picks the calendar called Team.",
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		entries, err := listCalendars(ctx, srv)
+		if err != nil {
+			fatal("Unable to list calendars", err)
+		}
+		calendarList = entries
+		if len(args) == 0 {
+			err = writeCalendars(os.Stdout, entries)
+		} else {
+			var id string
+			id, err = resolveCalendar(entries, args[0])
+			if err != nil {
+				fatal("Unable to find calendar", err)
+			}
+			entry := calendarEntry(id)
+			if entry == nil {
+				log.Fatalf("Unable to find calendar %q in your calendar list", id)
+			}
+			err = writeCalendar(os.Stdout, entry)
+		}
+		if err != nil {
+			fatal("Unable to write calendars", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(calendarsCmd)
+}
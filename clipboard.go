@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommand returns the first of the platform's clipboard tools that
+// is installed: pbcopy on macOS, clip on Windows, and wl-copy, xclip or xsel
+// elsewhere, wl-copy only under Wayland.
+func clipboardCommand() (*exec.Cmd, error) {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	for _, c := range candidates {
+		if path, err := exec.LookPath(c[0]); err == nil {
+			return exec.Command(path, c[1:]...), nil
+		}
+	}
+	if runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		return nil, errors.New("no clipboard tool found; install wl-clipboard, xclip or xsel")
+	}
+	return nil, errors.New("no clipboard tool found")
+}
+
+// copyToClipboard puts text on the system clipboard.
+func copyToClipboard(text string) error {
+	cmd, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The clone command's flags.
+var (
+	cloneSearch   string
+	cloneTitle    string
+	cloneStart    string
+	cloneEnd      string
+	cloneDuration time.Duration
+)
+
+// cloneTimes gives dup item's length from start on, or the --end or
+// --duration given. All-day events keep their number of days.
+func cloneTimes(dup, item *calendar.Event, start, now time.Time) error {
+	if item.Start != nil && item.Start.Date != "" {
+		from := eventStart(item)
+		days := int(eventEnd(item).Sub(from).Hours()/24 + 0.5)
+		dup.Start = &calendar.EventDateTime{Date: start.Format("2006-01-02")}
+		dup.End = &calendar.EventDateTime{Date: start.AddDate(0, 0, max(days, 1)).Format("2006-01-02")}
+		return nil
+	}
+	from, to, ok := eventTimes(item)
+	if !ok {
+		return fmt.Errorf("the event has no start or end")
+	}
+	end := start.Add(to.Sub(from))
+	switch {
+	case cloneEnd != "":
+		var err error
+		if end, err = parseDate(cloneEnd, now); err != nil {
+			return fmt.Errorf("--end: %w", err)
+		}
+	case cloneDuration > 0:
+		end = start.Add(cloneDuration)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("the event must end after it starts")
+	}
+	dup.Start = &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: item.Start.TimeZone}
+	dup.End = &calendar.EventDateTime{DateTime: end.Format(time.RFC3339), TimeZone: item.End.TimeZone}
+	return nil
+}
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone [EVENT-ID] --start TIME",
+	Short: "Copy an event to a new time",
+	Long: "Create a new event on the same calendar as an existing one, found by ID or with --search by title, with " +
+		"its title, place, description, guests, color and reminders. A Google Meet call gets a new link.\n\n" +
+		"The copy lasts as long as the original unless --end or --duration is given. Copying an occurrence of " +
+		"a recurring event makes a one-off event. The guests are sent invitations.",
+	Example: `  go-gcal-cli clone abc123 --start "next tuesday 10:00"
+  go-gcal-cli clone --search "planning" --start "friday 14:00" --duration 1h --title "Planning, part 2"`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		if cloneStart == "" {
+			usageFatalf("--start is required; try %s", dateExamples)
+		}
+		now := time.Now()
+		start, err := parseDate(cloneStart, now)
+		if err != nil {
+			usageFatalf("Invalid --start: %v", err)
+		}
+		requireScopes(writeScope)
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		calendarID, item, err := resolveEvent(ctx, srv, id, cloneSearch)
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		dup, err := events.Copy(item)
+		if err != nil {
+			fatal("Unable to copy event", err)
+		}
+		if cloneTitle != "" {
+			dup.Summary = cloneTitle
+		}
+		if err := cloneTimes(dup, item, start, now); err != nil {
+			usageFatalf("Invalid time: %v", err)
+		}
+		created, err := events.Insert(ctx, srv, calendarID, dup, callOptions()...)
+		if err != nil {
+			fatal("Unable to create event", scopeError(err))
+		}
+		printCreated(created)
+	},
+}
+
+func init() {
+	f := cloneCmd.Flags()
+	f.StringVar(&cloneSearch, "search", "", "find the event by title instead of ID")
+	f.StringVar(&cloneTitle, "title", "", "title of the copy instead of the original's")
+	f.StringVar(&cloneStart, "start", "", "when the copy starts (e.g. \"next tuesday 10:00\")")
+	f.StringVar(&cloneEnd, "end", "", "when the copy ends, instead of the original's length")
+	f.DurationVar(&cloneDuration, "duration", 0, "how long the copy lasts, instead of the original's length")
+	rootCmd.AddCommand(cloneCmd)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "go-gcal-cli",
+	Short: "Show your Google Calendar in the terminal",
+	Long: "Show your Google Calendar in the terminal.\n\n" +
+		"Without a subcommand it behaves like list.\n\n" +
+		"Exit codes: 1 for most failures, 2 for bad flags or arguments, 3 when authorization failed, 4 when a calendar or event " +
+		"was not found, 5 when the API's rate limit was hit and 6 when the API could not be reached.",
+	Args:              cobra.NoArgs,
+	PersistentPreRun:  checkGlobalFlags,
+	Run:               runList,
+	CompletionOptions: cobra.CompletionOptions{HiddenDefaultCmd: true},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the meetings that are on now and coming up next",
+	Args:  cobra.NoArgs,
+	Run:   runList,
+}
+
+// agendaDays is the agenda command's --days flag.
+var agendaDays int
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "List every event in a date range, grouped by day",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := runAgenda(ctx, srv, os.Stdout); err != nil {
+			fatal("Unable to show agenda", err)
+		}
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Report events added, removed or rescheduled since the previous listing",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := runDiff(ctx, srv, os.Stdout, *outputFormat == "json"); err != nil {
+			fatal("Unable to diff events", err)
+		}
+	},
+}
+
+// tuiRefresh is the tui command's --refresh flag.
+var tuiRefresh time.Duration
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse the calendar day by day in an interactive view",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		applyPlainTUI()
+		runBubbleTea(newService(), tuiRefresh)
+	},
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named account profiles, each with its own token",
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles, marking the active one",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		set, err := loadProfiles()
+		if err != nil {
+			fatal("Unable to read profiles", err)
+		}
+		active := activeProfile()
+		for _, name := range set.names() {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			calendar := set.Profiles[name].Calendar
+			if calendar == "" {
+				calendar = "primary"
+			}
+			fmt.Printf("%s %-20s %s\n", marker, name, calendar)
+		}
+	},
+}
+
+// profileCalendar is the profile add command's --default-calendar flag.
+var profileCalendar string
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a profile or change its default calendar",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validProfileName(args[0]); err != nil {
+			usageFatalf("Invalid profile name: %v", err)
+		}
+		set, err := loadProfiles()
+		if err != nil {
+			fatal("Unable to read profiles", err)
+		}
+		set.Profiles[args[0]] = profile{Calendar: profileCalendar}
+		if err := saveProfiles(set); err != nil {
+			fatal("Unable to save profiles", err)
+		}
+		fmt.Printf("Added profile %s; it will authorize on first use with --profile %s.\n", args[0], args[0])
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a profile and its saved token",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		set, err := loadProfiles()
+		if err != nil {
+			fatal("Unable to read profiles", err)
+		}
+		if _, ok := set.Profiles[name]; !ok {
+			usageFatalf("Unknown profile %q", name)
+		}
+		delete(set.Profiles, name)
+		if err := saveProfiles(set); err != nil {
+			fatal("Unable to save profiles", err)
+		}
+
+		// Forget the token as well, so a re-added profile authorizes afresh.
+		*profileFlag = name
+		if store, err := openTokenStore(); err == nil {
+			if err := store.Delete(); err != nil {
+				log.Printf("Unable to delete token for %s: %v", name, err)
+			}
+		}
+		fmt.Printf("Removed profile %s.\n", name)
+	},
+}
+
+func init() {
+	globalFlags.Var(&scopeFlags, "scope", "OAuth scope to request: readonly (default), events, full, contacts, directory or a scope URL; repeatable")
+	globalFlags.Var(&calendarFlags, "calendar", "calendar ID or name to list (default primary); repeatable")
+	globalFlags.IntVar(limitFlag, "max-rows", *limitFlag, "most events the table shows")
+	globalFlags.MarkDeprecated("max-rows", "use --limit instead")
+	rootCmd.PersistentFlags().AddFlagSet(globalFlags)
+	agendaCmd.Flags().IntVar(&agendaDays, "days", 1, "number of days to show; ignored when --to is set")
+	tuiCmd.Flags().DurationVar(&tuiRefresh, "refresh", time.Minute, "re-fetch events this often; 0 turns auto-refresh off")
+	profileAddCmd.Flags().StringVar(&profileCalendar, "default-calendar", "", "calendar ID listed by default for this profile")
+	profileCmd.AddCommand(profileListCmd, profileAddCmd, profileRemoveCmd)
+	rootCmd.AddCommand(listCmd, agendaCmd, diffCmd, tuiCmd, profileCmd)
+}
+
+// checkGlobalFlags fills in defaults from the environment and config file,
+// validates flags and runs the config migration before any command.
+func checkGlobalFlags(cmd *cobra.Command, args []string) {
+	// The environment goes first: it may move the config file with
+	// GCAL_CONFIG_DIR.
+	if err := applyEnv(globalFlags); err != nil {
+		fatal("Invalid environment", &UsageError{err})
+	}
+	// The config commands must be able to repair a broken file.
+	s := settings{}
+	if cmd.Parent() != configCmd {
+		var err error
+		if s, err = loadSettings(); err != nil {
+			fatal("Unable to read config file", err)
+		}
+		if err := applySettings(globalFlags, s); err != nil {
+			fatal("Invalid config file", &UsageError{err})
+		}
+	}
+	if err := applyTheme(*themeFlag, s); err != nil {
+		fatal("Invalid --theme", &UsageError{err})
+	}
+	applyPlainOutput()
+
+	if err := validLinkSource(*linkSource); err != nil {
+		fatal("Invalid --link-source", &UsageError{err})
+	}
+	if err := validOutput(*outputFormat); err != nil {
+		fatal("Invalid --output", &UsageError{err})
+	}
+	if _, err := parseColumns(*columnsFlag); err != nil {
+		fatal("Invalid --columns", &UsageError{err})
+	}
+	if *colorFilter != "" {
+		if _, err := eventColorID(*colorFilter); err != nil {
+			fatal("Invalid --color-filter", &UsageError{err})
+		}
+	}
+	if err := validAllDay(*allDayFlag); err != nil {
+		fatal("Invalid --all-day", &UsageError{err})
+	}
+	if _, err := configuredHours(); err != nil {
+		fatal("Invalid working hours", &UsageError{err})
+	}
+	if err := validOutOfHours(*outOfHours); err != nil {
+		fatal("Invalid --out-of-hours", &UsageError{err})
+	}
+	if err := validTokenStore(*tokenStoreFlag); err != nil {
+		fatal("Invalid --token-store", &UsageError{err})
+	}
+
+	if err := validAuthFlow(*authFlow); err != nil {
+		fatal("Invalid --auth-flow", &UsageError{err})
+	}
+
+	if *tzFlag != "" {
+		loc, err := time.LoadLocation(*tzFlag)
+		if err != nil {
+			fatal("Invalid --tz", &UsageError{err})
+		}
+		time.Local = loc
+	}
+
+	if *impersonate != "" && *serviceAccount == "" {
+		usageFatalf("--impersonate requires --service-account")
+	}
+	if *maxAttempts < 1 {
+		usageFatalf("--max-attempts must be at least 1")
+	}
+	if *parallel < 1 {
+		usageFatalf("--parallel must be at least 1")
+	}
+	if *offline && *noCache {
+		usageFatalf("--offline needs the event cache; drop --no-cache")
+	}
+
+	// This covers a profile picked with $GCAL_PROFILE as well.
+	if err := validProfileName(activeProfile()); err != nil {
+		usageFatalf("Invalid --profile: %v", err)
+	}
+	// Profile management must work with a profile that does not exist yet.
+	if cmd.Parent() != profileCmd {
+		if _, _, err := currentProfile(); err != nil {
+			fatal("Invalid profile", err)
+		}
+	}
+
+	if err := migrateConfig(os.Stderr, *migrate); err != nil {
+		fatal("Unable to migrate config files", err)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// eventColorNames are the names the web UI gives the event colors. The
+// Colors API only knows them by ID.
+var eventColorNames = map[string]string{
+	"1": "Lavender", "2": "Sage", "3": "Grape", "4": "Flamingo", "5": "Banana", "6": "Tangerine",
+	"7": "Peacock", "8": "Graphite", "9": "Blueberry", "10": "Basil", "11": "Tomato",
+}
+
+// calendarColorName selects events shown in their calendar's color.
+const calendarColorName = "none"
+
+// eventPalette maps event color IDs to the hex codes the Colors API gives
+// them, once loadEventPalette has fetched them.
+var eventPalette map[string]string
+
+// eventColorID looks up an event color by name, ignoring case, or by ID.
+// calendarColorName stands for the calendar's color, which is no ID at all.
+func eventColorID(name string) (string, error) {
+	if strings.EqualFold(name, calendarColorName) {
+		return "", nil
+	}
+	for id, n := range eventColorNames {
+		if strings.EqualFold(n, name) || id == name {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("unknown color %q; use %s or %s", name, strings.Join(colorNames(), ", "), calendarColorName)
+}
+
+// colorNames lists the event colors in ID order.
+func colorNames() []string {
+	var names []string
+	for id := 1; id <= len(eventColorNames); id++ {
+		names = append(names, strings.ToLower(eventColorNames[strconv.Itoa(id)]))
+	}
+	return names
+}
+
+// loadEventPalette fetches the event colors' hex codes once.
+func loadEventPalette(ctx context.Context, srv *calendar.Service) error {
+	if eventPalette != nil {
+		return nil
+	}
+	got, err := srv.Colors.Get().Context(ctx).Do(callOptions()...)
+	if err != nil {
+		return err
+	}
+	eventPalette = map[string]string{}
+	for id, def := range got.Event {
+		eventPalette[id] = def.Background
+	}
+	return nil
+}
+
+// eventColorName names item's color, "" when it has its calendar's.
+func eventColorName(item *calendar.Event) string {
+	return eventColorNames[item.ColorId]
+}
+
+// colorCell is the table's color column: a swatch with the event color's
+// name, or with its calendar's name when it has no color of its own.
+func colorCell(item *calendar.Event) string {
+	if name := eventColorName(item); name != "" {
+		return "■ " + name
+	}
+	return "■ " + calendarName(eventCalendar(item))
+}
+
+// eventColor returns the color item is shown in, its own or its calendar's,
+// or "" when unknown.
+func eventColor(item *calendar.Event) lipgloss.Color {
+	if item.ColorId != "" {
+		return lipgloss.Color(eventPalette[item.ColorId])
+	}
+	return calendarColor(eventCalendar(item))
+}
+
+// loadColorColumn fetches what the table's color column is drawn with, when
+// it has one. Without it the column goes uncolored.
+func loadColorColumn(ctx context.Context, srv *calendar.Service) {
+	if !slices.Contains(tableColumns(), "color") {
+		return
+	}
+	if entries, _, err := cachedCalendars(ctx, srv, false); err == nil && calendarList == nil {
+		calendarList = entries
+	}
+	_ = loadEventPalette(ctx, srv)
+}
+
+// filterColor keeps the events of the color named by --color-filter.
+func filterColor(items []*calendar.Event) []*calendar.Event {
+	if *colorFilter == "" {
+		return items
+	}
+	// checkGlobalFlags has validated it.
+	id, _ := eventColorID(*colorFilter)
+	var kept []*calendar.Event
+	for _, item := range items {
+		if item.ColorId == id {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+var colorsCmd = &cobra.Command{
+	Use:   "colors",
+	Short: "List the event colors for edit --color and --color-filter",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := loadEventPalette(ctx, srv); err != nil {
+			fatal("Unable to retrieve colors", err)
+		}
+		ids := make([]string, 0, len(eventPalette))
+		for id := range eventPalette {
+			ids = append(ids, id)
+		}
+		slices.SortFunc(ids, func(a, b string) int {
+			x, _ := strconv.Atoi(a)
+			y, _ := strconv.Atoi(b)
+			return x - y
+		})
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if !*noHeader {
+			fmt.Fprintln(tw, "ID\tNAME\tCOLOR")
+		}
+		for _, id := range ids {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", id, strings.ToLower(eventColorNames[id]), colorSwatch(eventPalette[id]))
+		}
+		if err := tw.Flush(); err != nil {
+			fatal("Unable to write colors", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(colorsCmd)
+}
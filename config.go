@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFile = "config.yaml"
+	envPrefix  = "GCAL_"
+)
+
+// settings is the config file: defaults keyed by global flag name, e.g.
+//
+//	calendar: [primary, team@example.com]
+//	tz: Europe/Berlin
+//	max-rows: 10
+//	output: table
+//
+// The sections in sectionSettings are the exception. Flags given on the
+// command line win over $GCAL_* variables, which win over the file.
+type settings map[string]any
+
+// sectionSettings are the top-level keys that are not flag names: themes,
+// which defines --theme values, and hooks; see userThemes and parseHooks.
+var sectionSettings = map[string]bool{themesSetting: true, hooksSetting: true}
+
+func configFilePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFile), nil
+}
+
+func loadSettings() (settings, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := settings{}
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func saveSettings(s settings) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// applySettings sets every flag in fs that was not given on the command
+// line from s. Lists set repeatable flags once per element.
+func applySettings(fs *pflag.FlagSet, s settings) error {
+	for name, value := range s {
+		if sectionSettings[name] {
+			continue
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		if f.Changed {
+			continue
+		}
+		values, ok := value.([]any)
+		if !ok {
+			values = []any{value}
+		}
+		for _, v := range values {
+			if err := f.Value.Set(fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// reloadSettings re-reads the config file into every flag in fs that was not
+// given on the command line or in the environment, resetting first those the
+// file no longer sets.
+func reloadSettings(fs *pflag.FlagSet) (settings, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	var reset error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		if list, ok := f.Value.(*stringList); ok {
+			*list = nil
+		} else if err := f.Value.Set(f.DefValue); err != nil && reset == nil {
+			reset = fmt.Errorf("%s: %w", f.Name, err)
+		}
+	})
+	if reset != nil {
+		return nil, reset
+	}
+	return s, applySettings(fs, s)
+}
+
+// envName returns the variable overriding flag name, e.g. GCAL_MAX_ROWS for
+// --max-rows.
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyEnv sets every flag in fs that was not given on the command line from
+// its $GCAL_* variable. Repeatable flags take a comma-separated list. Flags
+// set this way count as changed, so the config file does not override them.
+func applyEnv(fs *pflag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok || f.Changed || err != nil {
+			return
+		}
+		values := []string{value}
+		if f.Value.Type() == "strings" {
+			values = strings.Split(value, ",")
+		}
+		for _, v := range values {
+			if serr := fs.Set(f.Name, strings.TrimSpace(v)); serr != nil {
+				err = fmt.Errorf("%s: %w", envName(f.Name), serr)
+				return
+			}
+		}
+	})
+	return err
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show or change the defaults in the config file",
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print where the config file lives",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := configFilePath()
+		if err != nil {
+			fatal("Unable to find config directory", err)
+		}
+		fmt.Println(path)
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the settings in the config file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := loadSettings()
+		if err != nil {
+			fatal("Unable to read config file", err)
+		}
+		b, err := yaml.Marshal(s)
+		if err != nil {
+			fatal("Unable to format settings", err)
+		}
+		os.Stdout.Write(b)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set NAME VALUE...",
+	Short: "Save a default for a global flag; several values make a list",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		f := globalFlags.Lookup(name)
+		if f == nil {
+			usageFatalf("Unknown setting %q; settings are named after global flags", name)
+		}
+		var values []any
+		for _, arg := range args[1:] {
+			if err := f.Value.Set(arg); err != nil {
+				usageFatalf("Invalid value for %s: %v", name, err)
+			}
+			// Keep numbers and booleans typed in the file.
+			var v any
+			if err := yaml.Unmarshal([]byte(arg), &v); err != nil || v == nil {
+				v = arg
+			}
+			values = append(values, v)
+		}
+		s, err := loadSettings()
+		if err != nil {
+			fatal("Unable to read config file", err)
+		}
+		if len(values) == 1 {
+			s[name] = values[0]
+		} else {
+			s[name] = values
+		}
+		if err := saveSettings(s); err != nil {
+			fatal("Unable to save config file", err)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset NAME",
+	Short: "Remove a default from the config file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := loadSettings()
+		if err != nil {
+			fatal("Unable to read config file", err)
+		}
+		delete(s, args[0])
+		if err := saveSettings(s); err != nil {
+			fatal("Unable to save config file", err)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configPathCmd, configShowCmd, configSetCmd, configUnsetCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+const conflictMarker = "⚠"
+
+// declinedBySelf reports whether the authenticated user declined item.
+func declinedBySelf(item *calendar.Event) bool {
+	for _, attendee := range item.Attendees {
+		if attendee.Self && attendee.ResponseStatus == "declined" {
+			return true
+		}
+	}
+	return false
+}
+
+// blocksTime reports whether item should count towards double-bookings.
+// Events marked as free and invitations the user declined do not.
+func blocksTime(item *calendar.Event) bool {
+	return item.Transparency != "transparent" && !declinedBySelf(item) && !isRecurringMaster(item)
+}
+
+// sameEvent reports whether a and b are copies of one meeting, as when it
+// is on several of the listed calendars.
+func sameEvent(a, b *calendar.Event) bool {
+	return a.ICalUID != "" && a.ICalUID == b.ICalUID && eventStart(a).Equal(eventStart(b))
+}
+
+// findConflicts returns the set of timed events whose ranges overlap at least
+// one other event.
+func findConflicts(items []*calendar.Event) map[*calendar.Event]bool {
+	type span struct {
+		item       *calendar.Event
+		start, end int64
+	}
+	var spans []span
+	for _, item := range items {
+		startTime, endTime, ok := eventTimes(item)
+		if !ok || !blocksTime(item) {
+			continue
+		}
+		spans = append(spans, span{item, startTime.Unix(), endTime.Unix()})
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	conflicts := map[*calendar.Event]bool{}
+	for i := range spans {
+		for j := i + 1; j < len(spans) && spans[j].start < spans[i].end; j++ {
+			if sameEvent(spans[i].item, spans[j].item) {
+				continue
+			}
+			conflicts[spans[i].item] = true
+			conflicts[spans[j].item] = true
+		}
+	}
+	return conflicts
+}
+
+// markConflicts prefixes overlapping events with conflictMarker and returns
+// how many of them have not ended yet. With only set, all other events are
+// dropped.
+func markConflicts(items []*calendar.Event, only bool, now time.Time) ([]*calendar.Event, int) {
+	conflicts := findConflicts(items)
+	var kept []*calendar.Event
+	upcoming := 0
+	for _, item := range items {
+		if conflicts[item] {
+			item.Summary = conflictMarker + item.Summary
+			if _, endTime, _ := eventTimes(item); endTime.After(now) {
+				upcoming++
+			}
+		} else if only {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept, upcoming
+}
+
+// acceptedBySelf reports whether the user has committed to item: accepted
+// it, organizes it or has it to themselves.
+func acceptedBySelf(item *calendar.Event) bool {
+	if len(item.Attendees) == 0 || item.Organizer != nil && item.Organizer.Self {
+		return true
+	}
+	for _, attendee := range item.Attendees {
+		if attendee.Self {
+			return attendee.ResponseStatus == "accepted"
+		}
+	}
+	return false
+}
+
+// conflict is a pair of events that overlap. Accepted is set when the user
+// has committed to both, which makes it a real double-booking rather than an
+// invitation still to sort out.
+type conflict struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Accepted bool      `json:"accepted"`
+	Events   [2]Event  `json:"events"`
+}
+
+// findConflictPairs returns every overlapping pair of time-blocking events in
+// start order, with the overlap as the conflict's range.
+func findConflictPairs(items []*calendar.Event) []conflict {
+	var timed []*calendar.Event
+	for _, item := range items {
+		if _, _, ok := eventTimes(item); ok && blocksTime(item) {
+			timed = append(timed, item)
+		}
+	}
+	sortByStart(timed)
+
+	var pairs []conflict
+	for i, a := range timed {
+		_, aEnd, _ := eventTimes(a)
+		for _, b := range timed[i+1:] {
+			bStart, bEnd, _ := eventTimes(b)
+			if !bStart.Before(aEnd) {
+				break
+			}
+			if sameEvent(a, b) {
+				continue
+			}
+			c := conflict{Start: bStart, End: aEnd, Accepted: acceptedBySelf(a) && acceptedBySelf(b)}
+			if bEnd.Before(c.End) {
+				c.End = bEnd
+			}
+			c.Events = [2]Event{newEvent(a), newEvent(b)}
+			pairs = append(pairs, c)
+		}
+	}
+	return pairs
+}
+
+// writeConflicts lists each pair on one line, or as JSON with --output json.
+func writeConflicts(w io.Writer, pairs []conflict) error {
+	if *outputFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if pairs == nil {
+			pairs = []conflict{}
+		}
+		return enc.Encode(pairs)
+	}
+	if len(pairs) == 0 {
+		fmt.Fprintln(w, "No conflicts found.")
+		return nil
+	}
+	for _, c := range pairs {
+		kind := "overlap"
+		style := NormalStyle
+		if c.Accepted {
+			kind, style = "double-booked", ConflictRowStyle
+		}
+		line := fmt.Sprintf("%s %s  %-13s  %s ⟷ %s", conflictMarker, period{Start: c.Start, End: c.End}, kind, c.Events[0].Summary, c.Events[1].Summary)
+		fmt.Fprintln(w, style.Render(line))
+	}
+	return nil
+}
+
+// conflictsDays is the conflicts command's --days flag.
+var conflictsDays int
+
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "Report overlapping meetings over the coming days",
+	Long: "Scan the selected calendars, or all with --all-calendars, from now over --days days and list every pair of " +
+		"overlapping events. Pairs you have accepted both of are reported as double-booked, others as overlaps. " +
+		"Events marked free and declined invitations are ignored, as are copies of one meeting on several calendars.\n\n" +
+		"--output json prints the pairs for scripts.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if conflictsDays < 1 {
+			usageFatalf("--days must be at least 1")
+		}
+		now := time.Now()
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		events, err := fetchEvents(ctx, srv, now, startOfDay(now).AddDate(0, 0, conflictsDays))
+		if err != nil {
+			fatal("Unable to retrieve events", err)
+		}
+		if err := writeConflicts(os.Stdout, findConflictPairs(events.Items)); err != nil {
+			fatal("Unable to write conflicts", err)
+		}
+	},
+}
+
+func init() {
+	conflictsCmd.Flags().IntVar(&conflictsDays, "days", 7, "number of days to scan, starting today")
+	rootCmd.AddCommand(conflictsCmd)
+}
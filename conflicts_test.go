@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestFindConflicts(t *testing.T) {
+	base := time.Date(2024, 6, 3, 9, 0, 0, 0, time.Local)
+	at := func(summary string, start, end time.Duration) *calendar.Event {
+		return timed(summary, base, start, end)
+	}
+	transparent := at("Free", 30*time.Minute, 90*time.Minute)
+	transparent.Transparency = "transparent"
+	declined := at("Declined", 30*time.Minute, 90*time.Minute)
+	declined.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}
+
+	tests := []struct {
+		name  string
+		items []*calendar.Event
+		want  []string
+	}{
+		{"back to back", []*calendar.Event{at("A", 0, time.Hour), at("B", time.Hour, 2*time.Hour)}, nil},
+		{"overlap", []*calendar.Event{at("A", 0, time.Hour), at("B", 30*time.Minute, 2*time.Hour)}, []string{"A", "B"}},
+		{"contained", []*calendar.Event{at("A", 0, 3*time.Hour), at("B", time.Hour, 2*time.Hour), at("C", 3*time.Hour, 4*time.Hour)}, []string{"A", "B"}},
+		{"transparent", []*calendar.Event{at("A", 0, time.Hour), transparent}, nil},
+		{"declined", []*calendar.Event{at("A", 0, time.Hour), declined}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := findConflicts(tt.items)
+			if len(conflicts) != len(tt.want) {
+				t.Errorf("got %d conflicting events, want %d", len(conflicts), len(tt.want))
+			}
+			for _, item := range tt.items {
+				want := false
+				for _, s := range tt.want {
+					want = want || s == item.Summary
+				}
+				if conflicts[item] != want {
+					t.Errorf("%s conflicting = %v, want %v", item.Summary, conflicts[item], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/people/v1"
+)
+
+// contactScopes are what looking up guests by name needs: the user's
+// contacts and, on Workspace accounts, the domain's directory.
+var contactScopes = []string{people.ContactsReadonlyScope, people.DirectoryReadonlyScope}
+
+// contactFields are the parts of a person the lookups read.
+const contactFields = "names,emailAddresses"
+
+// contactLimit caps the matches offered per name.
+const contactLimit = 10
+
+// contact is one address a name can stand for.
+type contact struct {
+	Name  string
+	Email string
+}
+
+func (c contact) String() string {
+	if c.Name == "" {
+		return c.Email
+	}
+	return c.Name + " <" + c.Email + ">"
+}
+
+// isEmail tells addresses from names to look up.
+func isEmail(value string) bool {
+	return strings.Contains(strings.Trim(value, "@"), "@")
+}
+
+// requireContacts asks for contactScopes when values include names, so that
+// only users who rely on the lookup grant access to their contacts. Call it
+// before newService.
+func requireContacts(values []string) {
+	for _, v := range values {
+		if !isEmail(v) {
+			requireScopes(contactScopes...)
+			return
+		}
+	}
+}
+
+// newPeopleService returns a People API client authorized as newService's
+// Calendar client is.
+func newPeopleService() *people.Service {
+	srv, err := people.NewService(context.Background(), option.WithHTTPClient(retryClient(httpClient())))
+	if err != nil {
+		fatal("Unable to retrieve People client", err)
+	}
+	return srv
+}
+
+// personContacts lists the addresses of p.
+func personContacts(p *people.Person) []contact {
+	var name string
+	if len(p.Names) > 0 {
+		name = p.Names[0].DisplayName
+	}
+	var contacts []contact
+	for _, e := range p.EmailAddresses {
+		if e.Value != "" {
+			contacts = append(contacts, contact{Name: name, Email: e.Value})
+		}
+	}
+	return contacts
+}
+
+// searchContacts finds query among the user's contacts and in the domain's
+// directory. Accounts without a directory only search their contacts.
+func searchContacts(ctx context.Context, srv *people.Service, query string) ([]contact, error) {
+	// The API answers from a cache that an empty query brings up to date.
+	if _, err := srv.People.SearchContacts().Query("").ReadMask(contactFields).Context(ctx).Do(callOptions()...); err != nil {
+		return nil, err
+	}
+	found, err := srv.People.SearchContacts().Query(query).ReadMask(contactFields).PageSize(contactLimit).Context(ctx).Do(callOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	var persons []*people.Person
+	for _, r := range found.Results {
+		persons = append(persons, r.Person)
+	}
+	dir, err := srv.People.SearchDirectoryPeople().Query(query).ReadMask(contactFields).PageSize(contactLimit).
+		Sources("DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE", "DIRECTORY_SOURCE_TYPE_DOMAIN_CONTACT").Context(ctx).Do(callOptions()...)
+	var gerr *googleapi.Error
+	switch {
+	case err == nil:
+		persons = append(persons, dir.People...)
+	case !errors.As(err, &gerr) || gerr.Code >= 500:
+		return nil, err
+	}
+	var contacts []contact
+	seen := map[string]bool{}
+	for _, p := range persons {
+		for _, c := range personContacts(p) {
+			if key := strings.ToLower(c.Email); !seen[key] {
+				seen[key] = true
+				contacts = append(contacts, c)
+			}
+		}
+	}
+	return contacts, nil
+}
+
+// pickContact asks which of matches name stands for, the first by default.
+func pickContact(name string, matches []contact) (contact, error) {
+	fmt.Printf("%q matches:\n", name)
+	for i, c := range matches {
+		fmt.Printf("  %d. %s\n", i+1, c)
+	}
+	answer, err := ask(stdin, os.Stdout, "Which one", "1")
+	if err != nil {
+		return contact{}, err
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(matches) {
+		return contact{}, fmt.Errorf("no contact numbered %q", answer)
+	}
+	return matches[n-1], nil
+}
+
+// resolveAttendees replaces the names among values with the addresses they
+// stand for in the user's contacts or directory, asking when a name matches
+// several. Addresses are kept as they are.
+func resolveAttendees(ctx context.Context, values []string) ([]string, error) {
+	var srv *people.Service
+	resolved := make([]string, 0, len(values))
+	for _, v := range values {
+		if isEmail(v) {
+			resolved = append(resolved, v)
+			continue
+		}
+		if srv == nil {
+			srv = newPeopleService()
+		}
+		matches, err := searchContacts(ctx, srv, v)
+		if err != nil {
+			return nil, fmt.Errorf("looking up %q: %w", v, scopeError(err))
+		}
+		var c contact
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no contact matches %q", v)
+		case 1:
+			c = matches[0]
+		default:
+			if c, err = pickContact(v, matches); err != nil {
+				return nil, err
+			}
+		}
+		resolved = append(resolved, c.Email)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The create command's flags.
+var (
+	createTitle       string
+	createStart       string
+	createEnd         string
+	createDuration    time.Duration
+	createAllDay      bool
+	createLocation    string
+	createDescription string
+	createMeet        bool
+	createAttendees   stringList
+	createRepeat      string
+	createFromFile    string
+	createDryRun      bool
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an event on the first --calendar",
+	Long: "Create an event on the first --calendar from the flags below.\n\n" +
+		"With --from-file, create a batch of events instead, from CSV with a header row, TSV, or a JSON array of " +
+		"objects. The columns or keys are " + strings.Join(bulkFields, ", ") + ", taking the same values as the " +
+		"flags of the same names; attendees are separated by semicolons. Each event is reported as it is " +
+		"created, and rows that fail are reported without stopping the rest. --dry-run checks every row " +
+		"without creating anything.",
+	Example: `  go-gcal-cli create --title "1:1" --start "tomorrow 14:00" --duration 30m --attendee a@example.com --meet
+  go-gcal-cli create --title "Lunch" --start "friday 12:00" --attendee sam
+  go-gcal-cli create --title "Offsite" --start 2024-06-03 --all-day --duration 48h
+  go-gcal-cli create --title "Standup" --start "monday 9:30" --duration 15m --repeat "weekdays until dec 20"
+  go-gcal-cli create --title "Review" --start "friday 14:00" --repeat "FREQ=MONTHLY;BYDAY=-1FR"
+  go-gcal-cli create --from-file events.csv --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if createFromFile != "" {
+			runCreateFromFile(cmd)
+			return
+		}
+		if createDryRun {
+			usageFatalf("--dry-run only goes with --from-file")
+		}
+		spec, err := createSpec(time.Now())
+		if err != nil {
+			fatal("Invalid event", &UsageError{err})
+		}
+
+		requireScopes(writeScope)
+		requireContacts(spec.Attendees)
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if spec.Attendees, err = resolveAttendees(ctx, spec.Attendees); err != nil {
+			fatal("Unable to find guest", err)
+		}
+		if len(spec.Recurrence) > 0 && spec.TimeZone == "" {
+			// The API needs a zone to repeat events in; the calendar's is
+			// what the web UI uses.
+			cal, err := srv.Calendars.Get(calendarIDs()[0]).Fields("timeZone").Context(ctx).Do(callOptions()...)
+			if err != nil {
+				fatal("Unable to look up the calendar's time zone", err)
+			}
+			spec.TimeZone = cal.TimeZone
+		}
+		item, err := spec.Event()
+		if err != nil {
+			fatal("Invalid event", &UsageError{err})
+		}
+		created, err := events.Insert(ctx, srv, calendarIDs()[0], item, callOptions()...)
+		if err != nil {
+			fatal("Unable to create event", scopeError(err))
+		}
+		printCreated(created)
+	},
+}
+
+var quickCmd = &cobra.Command{
+	Use:     "quick TEXT...",
+	Short:   "Create an event from a sentence, as Google Calendar's quick add does",
+	Example: `  go-gcal-cli quick "Lunch with Sam tomorrow at noon"`,
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		requireScopes(writeScope)
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		created, err := events.QuickAdd(ctx, srv, calendarIDs()[0], strings.Join(args, " "), callOptions()...)
+		if err != nil {
+			fatal("Unable to create event", scopeError(err))
+		}
+		printCreated(created)
+	},
+}
+
+// runCreateFromFile is create --from-file. It exits 1 when any event
+// failed.
+func runCreateFromFile(cmd *cobra.Command) {
+	for _, name := range []string{"title", "start", "end", "duration", "all-day", "location", "description", "meet", "attendee", "repeat"} {
+		if cmd.Flags().Changed(name) {
+			usageFatalf("--%s does not go with --from-file; give it in the file", name)
+		}
+	}
+	rows, err := readBulkFile(createFromFile)
+	if err != nil {
+		log.Fatalf("Unable to read %s: %v", createFromFile, err)
+	}
+	if len(rows) == 0 {
+		log.Fatalf("No events in %s", createFromFile)
+	}
+	if !createDryRun {
+		requireScopes(writeScope)
+	}
+	for _, row := range rows {
+		requireContacts(splitAttendees(row.Fields["attendees"]))
+	}
+	srv := newService()
+	ctx, cancel := requestContext()
+	defer cancel()
+	failed, err := runBulk(ctx, srv, rows, os.Stdout, createDryRun)
+	if err != nil {
+		fatal("Unable to create events", err)
+	}
+	if failed > 0 {
+		os.Exit(exitFailure)
+	}
+}
+
+// createSpec assembles the event from the create flags. --end wins over
+// --duration.
+func createSpec(now time.Time) (events.Spec, error) {
+	spec := events.Spec{
+		Title:       createTitle,
+		Duration:    createDuration,
+		AllDay:      createAllDay,
+		TimeZone:    *tzFlag,
+		Location:    createLocation,
+		Description: createDescription,
+		Attendees:   createAttendees,
+		Meet:        createMeet,
+	}
+	if createStart == "" {
+		return spec, fmt.Errorf("--start is required; try %s", dateExamples)
+	}
+	var err error
+	if spec.Start, err = parseDate(createStart, now); err != nil {
+		return spec, fmt.Errorf("--start: %w", err)
+	}
+	if createEnd != "" {
+		end, err := parseDate(createEnd, now)
+		if err != nil {
+			return spec, fmt.Errorf("--end: %w", err)
+		}
+		if !end.After(spec.Start) {
+			return spec, fmt.Errorf("--end must be after --start")
+		}
+		spec.Duration = end.Sub(spec.Start)
+	}
+	if createRepeat != "" {
+		rule, err := parseRepeat(createRepeat, now, spec.AllDay)
+		if err != nil {
+			return spec, fmt.Errorf("--repeat: %w", err)
+		}
+		spec.Recurrence = []string{rule}
+	}
+	return spec, nil
+}
+
+// printCreated confirms a new event with its ID, for use with other
+// commands, and its link.
+func printCreated(item *calendar.Event) {
+	fmt.Printf("Created %s  %s\n", eventWhen(item), item.Summary)
+	fmt.Printf("ID:   %s\n", item.Id)
+	if len(item.Recurrence) > 0 {
+		fmt.Printf("Rule: %s\n", describeRecurrence(item.Recurrence))
+	}
+	if link := eventLink(item, *linkSource); link != "" {
+		fmt.Printf("Join: %s\n", link)
+	}
+	fmt.Printf("View: %s\n", item.HtmlLink)
+}
+
+func init() {
+	f := createCmd.Flags()
+	f.StringVar(&createTitle, "title", "", "event title")
+	f.StringVar(&createStart, "start", "", "when the event starts (e.g. \"tomorrow 14:00\", 2024-06-03)")
+	f.StringVar(&createEnd, "end", "", "when the event ends, instead of --duration")
+	f.DurationVar(&createDuration, "duration", 30*time.Minute, "how long the event lasts")
+	f.BoolVar(&createAllDay, "all-day", false, "create an all-day event on --start's date")
+	f.StringVar(&createLocation, "location", "", "where the event takes place")
+	f.StringVar(&createDescription, "description", "", "event description")
+	f.BoolVar(&createMeet, "meet", false, "add a Google Meet link")
+	f.Var(&createAttendees, "attendee", "email address or contact name to invite; repeatable")
+	f.StringVar(&createFromFile, "from-file", "", "create the events of a CSV, TSV or JSON file, - for stdin")
+	f.BoolVar(&createDryRun, "dry-run", false, "with --from-file, only check the events and list what would be created")
+	f.StringVar(&createRepeat, "repeat", "", "make the event recur, e.g. \"weekly on mon,wed until 2024-12-31\", weekdays or an RRULE")
+	rootCmd.AddCommand(createCmd, quickCmd)
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// daemonSocketPath is where a running daemon listens for control commands.
+// There is one per profile; a listening socket means one is running.
+func daemonSocketPath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileFileName("daemon", ".sock", activeProfile())), nil
+}
+
+// daemonStatus is what a running daemon reports to "daemon status".
+type daemonStatus struct {
+	PID       int       `json:"pid"`
+	Started   time.Time `json:"started"`
+	LastCheck time.Time `json:"last_check"`
+	LastError string    `json:"last_error,omitempty"`
+	Calendars []string  `json:"calendars"`
+}
+
+// daemonRequest sends one control command to the running daemon and returns
+// its reply.
+func daemonRequest(command string) (string, error) {
+	path, err := daemonSocketPath()
+	if err != nil {
+		return "", err
+	}
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("no daemon running for profile %s", activeProfile())
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && reply == "" {
+		return "", err
+	}
+	reply = strings.TrimSpace(reply)
+	if msg, ok := strings.CutPrefix(reply, "error: "); ok {
+		return "", errors.New(msg)
+	}
+	return reply, nil
+}
+
+// listenControl claims the control socket, failing if another daemon holds
+// it. A socket file left behind by a crash is replaced.
+func listenControl() (net.Listener, error) {
+	path, err := daemonSocketPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := daemonRequest("ping"); err == nil {
+		return nil, fmt.Errorf("a daemon is already running for profile %s", activeProfile())
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	os.Remove(path)
+	return net.Listen("unix", path)
+}
+
+// serveControl answers control commands until ln is closed: ping, status,
+// reload and stop.
+func serveControl(ln net.Listener, w *watcher, started time.Time, stop func()) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(10 * time.Second))
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.TrimSpace(line)
+			w.log.Debug("control command", "command", command)
+			switch command {
+			case "ping":
+				fmt.Fprintln(conn, "ok")
+			case "status":
+				last, calendars, lastErr := w.lastResult()
+				status := daemonStatus{PID: os.Getpid(), Started: started, LastCheck: last, Calendars: calendars}
+				if lastErr != nil {
+					status.LastError = lastErr.Error()
+				}
+				b, _ := json.Marshal(status)
+				fmt.Fprintf(conn, "%s\n", b)
+			case "reload":
+				w.requestReload()
+				fmt.Fprintln(conn, "ok")
+			case "stop":
+				fmt.Fprintln(conn, "ok")
+				stop()
+			default:
+				fmt.Fprintf(conn, "error: unknown command %q\n", command)
+			}
+		}()
+	}
+}
+
+// reloadDaemon re-reads the config file and resolves the calendars again,
+// as on SIGHUP.
+func reloadDaemon(ctx context.Context, srv *calendar.Service, w *watcher) error {
+	s, err := reloadSettings(globalFlags)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	if w.hooks, err = parseHooks(s); err != nil {
+		return err
+	}
+	calendarList = nil
+	if err := resolveCalendarNames(ctx, srv); err != nil {
+		return err
+	}
+	if len(w.openLink) > 0 {
+		entries, _, err := cachedCalendars(ctx, srv, false)
+		if err != nil {
+			return err
+		}
+		calendarList = entries
+	}
+	return nil
+}
+
+// daemonLogFormat is the daemon command's --log-format flag.
+var daemonLogFormat string
+
+// daemonLogger logs to stderr, where systemd and launchd pick it up, as
+// logfmt-style text or JSON.
+func daemonLogger(format string) (*slog.Logger, error) {
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, nil)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q; use text or json", format)
+}
+
+// runDaemon runs the watcher until SIGINT, SIGTERM or "daemon stop". SIGHUP
+// and "daemon reload" re-read the config file.
+func runDaemon(srv *calendar.Service, logger *slog.Logger) error {
+	w, err := setupWatcher(srv)
+	if err != nil {
+		return err
+	}
+	w.log = logger
+	w.reload = func(ctx context.Context) error { return reloadDaemon(ctx, srv, w) }
+
+	ln, err := listenControl()
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	started := time.Now()
+	go serveControl(ln, w, started, stop)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == syscall.SIGHUP {
+					logger.Info("reload requested", "signal", sig.String())
+					w.requestReload()
+					continue
+				}
+				logger.Info("stopping", "signal", sig.String())
+				stop()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	logger.Info("started", "pid", os.Getpid(), "profile", activeProfile(), "calendars", calendarIDs(), "before", watchBefore)
+	w.run(ctx)
+	logger.Info("stopped")
+	return nil
+}
+
+// systemdUnit is the user unit daemon install writes on Linux.
+var systemdUnit = template.Must(template.New("unit").Parse(`[Unit]
+Description=go-gcal-cli meeting notifications
+After=network-online.target
+
+[Service]
+ExecStart={{.ExecStart}}
+ExecReload=/bin/kill -HUP $MAINPID
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=default.target
+`))
+
+// launchdPlist is the launch agent daemon install writes on macOS.
+var launchdPlist = template.Must(template.New("plist").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{.Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Args}}
+		<string>{{.}}</string>
+{{- end}}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>{{.Log}}</string>
+</dict>
+</plist>
+`))
+
+// systemdQuote quotes an ExecStart argument, escaping what systemd would
+// otherwise expand.
+func systemdQuote(arg string) string {
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(arg)
+	return `"` + arg + `"`
+}
+
+// daemonArgs is the command line the service runs: this binary, the
+// profile and config directory in use, "daemon" and extra.
+func daemonArgs(extra []string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return nil, err
+	}
+	args := []string{exe}
+	if *profileFlag != "" {
+		args = append(args, "--profile", *profileFlag)
+	}
+	if *configDirFlag != "" {
+		dir, err := filepath.Abs(*configDirFlag)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--config-dir", dir)
+	}
+	return append(append(args, "daemon"), extra...), nil
+}
+
+// installService writes the systemd user unit or launchd agent that runs the
+// daemon and returns its path and how to start it.
+func installService(extra []string, w io.Writer) error {
+	args, err := daemonArgs(extra)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	name := profileFileName(appName, "", activeProfile())
+
+	var path, start string
+	var render func(io.Writer) error
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(dir, "systemd", "user", name+".service")
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = systemdQuote(arg)
+		}
+		render = func(out io.Writer) error {
+			return systemdUnit.Execute(out, map[string]string{"ExecStart": strings.Join(quoted, " ")})
+		}
+		start = fmt.Sprintf("systemctl --user daemon-reload && systemctl --user enable --now %s", name)
+	case "darwin":
+		label := "com.github.sinanawad." + name
+		path = filepath.Join(home, "Library", "LaunchAgents", label+".plist")
+		logDir, err := cacheDir()
+		if err != nil {
+			return err
+		}
+		escaped := make([]string, len(args))
+		for i, arg := range args {
+			escaped[i] = xmlEscape(arg)
+		}
+		render = func(out io.Writer) error {
+			return launchdPlist.Execute(out, map[string]any{"Label": label, "Args": escaped, "Log": xmlEscape(filepath.Join(logDir, "daemon.log"))})
+		}
+		start = fmt.Sprintf("launchctl load -w %s", path)
+	default:
+		return fmt.Errorf("daemon install supports systemd and launchd, not %s; run \"daemon\" from your own service manager", runtime.GOOS)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Wrote %s\nStart it with:\n  %s\n", path, start)
+	return nil
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run watch as a background service",
+	Long: "Run the watch loop as a long-lived service with structured logs on stderr.\n\n" +
+		"One daemon runs per profile; it listens on a control socket in the cache directory that the status, reload and " +
+		"stop subcommands talk to. SIGHUP re-reads the config file, as does \"daemon reload\".\n\n" +
+		"\"daemon install\" sets it up as a systemd user service or launchd agent.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		logger, err := daemonLogger(daemonLogFormat)
+		if err != nil {
+			fatal("Invalid --log-format", &UsageError{err})
+		}
+		slog.SetDefault(logger)
+		if err := runDaemon(newService(), logger); err != nil {
+			fatal("Unable to run daemon", err)
+		}
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the daemon is running and when it last checked",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		reply, err := daemonRequest("status")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if *outputFormat == "json" {
+			fmt.Println(reply)
+			return
+		}
+		var status daemonStatus
+		if err := json.Unmarshal([]byte(reply), &status); err != nil {
+			fatal("Unable to read daemon status", err)
+		}
+		fmt.Printf("Running as pid %d since %s\n", status.PID, status.Started.Local().Format("Mon 2006-01-02 15:04"))
+		fmt.Printf("Calendars:  %s\n", strings.Join(status.Calendars, ", "))
+		if !status.LastCheck.IsZero() {
+			fmt.Printf("Last check: %s\n", status.LastCheck.Local().Format("15:04:05"))
+		}
+		if status.LastError != "" {
+			fmt.Printf("Last error: %s\n", status.LastError)
+		}
+	},
+}
+
+// daemonControlCmd returns a subcommand that sends command to the daemon.
+func daemonControlCmd(command, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   command,
+		Short: short,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, err := daemonRequest(command); err != nil {
+				log.Fatalf("Unable to %s the daemon: %v", command, err)
+			}
+		},
+	}
+}
+
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install [-- DAEMON-FLAGS...]",
+	Short: "Install the daemon as a systemd user service or launchd agent",
+	Long: "Write a systemd user unit on Linux or a launchd agent on macOS that runs the daemon at login, " +
+		"with the current --profile and --config-dir. Flags after -- are passed to the daemon.",
+	Example: `  go-gcal-cli daemon install -- --before 2m --open-link 1m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := installService(args, os.Stdout); err != nil {
+			fatal("Unable to install the daemon", err)
+		}
+	},
+}
+
+func init() {
+	addWatchFlags(daemonCmd.Flags())
+	daemonCmd.Flags().StringVar(&daemonLogFormat, "log-format", "text", "log format: text or json")
+	daemonCmd.AddCommand(
+		daemonStatusCmd,
+		daemonControlCmd("reload", "Make the daemon re-read the config file"),
+		daemonControlCmd("stop", "Stop the daemon"),
+		daemonInstallCmd,
+	)
+	rootCmd.AddCommand(daemonCmd)
+}
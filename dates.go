@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateExamples is appended to parse errors so users can see what is accepted.
+const dateExamples = "today, tomorrow 9am, friday 3pm, in 2 weeks, eod, jun 3, +3d, 2024-06-03 14:00"
+
+var (
+	offsetPattern = regexp.MustCompile(`^([+-]\d+)([mhdw])$`)
+	clockPattern  = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	// relativePattern matches "in 2 weeks", "in an hour" and "3 days ago".
+	relativePattern = regexp.MustCompile(`^(?:in\s+(\d+|an?)\s+(minute|min|hour|day|week|month)s?|(\d+|an?)\s+(minute|min|hour|day|week|month)s?\s+ago)$`)
+	// monthDayPattern matches "jun 3", "june 3rd" and "3 june".
+	monthDayPattern = regexp.MustCompile(`^(?:([a-z]+)\s+(\d{1,2})(?:st|nd|rd|th)?|(\d{1,2})(?:st|nd|rd|th)?\s+([a-z]+))$`)
+)
+
+var absoluteLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseDate turns a date flag value into a time relative to now. It accepts
+// RFC3339 and plain dates as well as phrases like "tomorrow 9am",
+// "next monday", "friday at 3pm", "in 2 weeks", "eod", "jun 3" and "+3d".
+// Day keywords without a clock time resolve to midnight.
+func parseDate(value string, now time.Time) (time.Time, error) {
+	s := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty date; try %s", dateExamples)
+	}
+
+	// Before lowercasing, which RFC3339's T and Z do not survive.
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if s == "now" {
+		return now, nil
+	}
+
+	if m := offsetPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "m":
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case "h":
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case "d":
+			return now.AddDate(0, 0, n), nil
+		case "w":
+			return now.AddDate(0, 0, 7*n), nil
+		}
+	}
+
+	if t, ok := parseRelative(s, now); ok {
+		return t, nil
+	}
+	if t, ok := parsePeriodEnd(s, now); ok {
+		return t, nil
+	}
+
+	if day, ok := parseDay(s, now); ok {
+		return day, nil
+	}
+	if hour, minute, ok := parseClock(s); ok {
+		return atClock(now, hour, minute), nil
+	}
+
+	// "<day> <time>" and "<time> <day>", e.g. "tomorrow 9am", "next friday
+	// at 14:30" or "3pm friday".
+	words := strings.Split(strings.Replace(s, " at ", " ", 1), " ")
+	for i := 1; i < len(words); i++ {
+		dayPart, clockPart := strings.Join(words[:i], " "), strings.Join(words[i:], " ")
+		if hour, minute, ok := parseClock(clockPart); ok {
+			if day, ok := parseDay(dayPart, now); ok {
+				return atClock(day, hour, minute), nil
+			}
+		}
+		if hour, minute, ok := parseClock(dayPart); ok {
+			if day, ok := parseDay(clockPart, now); ok {
+				return atClock(day, hour, minute), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q; try %s", value, dateExamples)
+}
+
+// parseRelative resolves "in 2 weeks", "in an hour" and "3 days ago".
+func parseRelative(s string, now time.Time) (time.Time, bool) {
+	m := relativePattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	count, unit, sign := m[1], m[2], 1
+	if count == "" {
+		count, unit, sign = m[3], m[4], -1
+	}
+	n := 1
+	if count != "a" && count != "an" {
+		n, _ = strconv.Atoi(count)
+	}
+	n *= sign
+	switch unit {
+	case "minute", "min":
+		return now.Add(time.Duration(n) * time.Minute), true
+	case "hour":
+		return now.Add(time.Duration(n) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, n), true
+	case "week":
+		return now.AddDate(0, 0, 7*n), true
+	}
+	return now.AddDate(0, n, 0), true
+}
+
+// parsePeriodEnd resolves "eod", "eow" and "eom", also spelled out as "end
+// of day" and so on, to the midnight ending today, this week (on Sunday) or
+// this month, which suits them to --to.
+func parsePeriodEnd(s string, now time.Time) (time.Time, bool) {
+	today := startOfDay(now)
+	switch s {
+	case "eod", "end of day", "end of today":
+		return today.AddDate(0, 0, 1), true
+	case "eow", "end of week", "end of the week":
+		return today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7), true
+	case "eom", "end of month", "end of the month":
+		return today.AddDate(0, 1, 1-today.Day()), true
+	}
+	return time.Time{}, false
+}
+
+// parseDay resolves day keywords, weekdays and dates such as "jun 3" to
+// midnight of the matching day. Weekdays and dates without a year are the
+// next ones from today on.
+func parseDay(s string, now time.Time) (time.Time, bool) {
+	today := startOfDay(now)
+	switch s {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "this week":
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), true
+	case "last week":
+		return today.AddDate(0, 0, -7-(int(today.Weekday())+6)%7), true
+	case "next week":
+		// Monday of next week.
+		return today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7), true
+	case "next month":
+		return today.AddDate(0, 1, 1-today.Day()), true
+	}
+	if day, ok := parseMonthDay(s, today); ok {
+		return day, true
+	}
+
+	next := false
+	if rest, ok := strings.CutPrefix(s, "next "); ok {
+		s, next = rest, true
+	} else {
+		s = strings.TrimPrefix(s, "this ")
+	}
+	wd, ok := parseWeekday(s)
+	if !ok {
+		return time.Time{}, false
+	}
+	delta := (int(wd) - int(today.Weekday()) + 7) % 7
+	if next && delta == 0 {
+		delta = 7
+	}
+	return today.AddDate(0, 0, delta), true
+}
+
+// parseMonthDay resolves "jun 3" or "3 june" to that date this year, or
+// next year once it has passed.
+func parseMonthDay(s string, today time.Time) (time.Time, bool) {
+	m := monthDayPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	name, dayText := m[1], m[2]
+	if name == "" {
+		name, dayText = m[4], m[3]
+	}
+	month, ok := parseMonth(name)
+	if !ok {
+		return time.Time{}, false
+	}
+	day, _ := strconv.Atoi(dayText)
+	t := time.Date(today.Year(), month, day, 0, 0, 0, 0, today.Location())
+	if t.Month() != month {
+		// No such day in that month, such as "feb 30".
+		return time.Time{}, false
+	}
+	if t.Before(today) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
+
+func parseMonth(s string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if len(s) >= 3 && strings.HasPrefix(name, s) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses a time of day such as "9am", "9:30pm", "14:00" or
+// "noon" into an hour and minute.
+func parseClock(s string) (int, int, bool) {
+	switch s {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		// A bare number is too ambiguous to treat as a time of day.
+		return 0, 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+func startOfDay(t time.Time) time.Time {
+	return atClock(t, 0, 0)
+}
+
+// atClock returns the given time of day on t's date.
+func atClock(t time.Time, hour, minute int) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, hour, minute, 0, 0, t.Location())
+}
+
+// spanPattern matches lengths such as "3 days", "next 2 weeks" or "5d".
+var spanPattern = regexp.MustCompile(`^(?:next\s+)?(\d+)\s*(d|days?|w|weeks?)$`)
+
+// parseWithin turns a search horizon such as "next 3 days", "2 weeks",
+// "48h" or any parseDate value into its end. Day counts run to the end of
+// that day, so that "next 3 days" on a Monday covers Monday to Thursday.
+func parseWithin(value string, now time.Time) (time.Time, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	if m := spanPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if strings.HasPrefix(m[2], "w") {
+			n *= 7
+		}
+		return startOfDay(now).AddDate(0, 0, n+1), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	t, err := parseDate(value, now)
+	if err != nil {
+		return t, fmt.Errorf("unrecognized span %q; try next 3 days, 2w, 48h or %s", value, dateExamples)
+	}
+	return t, nil
+}
+
+// parseTimeRange turns a range such as "14:00-16:00" or "2pm–4pm" into
+// times on day's date.
+func parseTimeRange(value string, day time.Time) (time.Time, time.Time, error) {
+	from, to, ok := strings.Cut(strings.ReplaceAll(strings.ToLower(value), "–", "-"), "-")
+	if ok {
+		h1, m1, ok1 := parseClock(strings.TrimSpace(from))
+		h2, m2, ok2 := parseClock(strings.TrimSpace(to))
+		if ok1 && ok2 {
+			start, end := atClock(day, h1, m1), atClock(day, h2, m2)
+			if !end.After(start) {
+				return start, end, fmt.Errorf("%q ends before it starts", value)
+			}
+			return start, end, nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unrecognized time range %q; try 14:00-16:00 or 2pm-4pm", value)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2024, 6, 5, 10, 30, 0, 0, time.UTC)
+	day := func(month time.Month, d, hour, minute int) time.Time {
+		return time.Date(2024, month, d, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-06-10T09:00:00Z", day(6, 10, 9, 0)},
+		{"2024-06-10 14:00", day(6, 10, 14, 0)},
+		{"2024-06-10", day(6, 10, 0, 0)},
+		{"now", now},
+		{"  Today  ", day(6, 5, 0, 0)},
+		{"tomorrow", day(6, 6, 0, 0)},
+		{"yesterday", day(6, 4, 0, 0)},
+		{"+3d", day(6, 8, 10, 30)},
+		{"-2h", day(6, 5, 8, 30)},
+		{"+1w", day(6, 12, 10, 30)},
+		{"in 2 weeks", day(6, 19, 10, 30)},
+		{"in an hour", day(6, 5, 11, 30)},
+		{"3 days ago", day(6, 2, 10, 30)},
+		{"in 1 month", day(7, 5, 10, 30)},
+		{"eod", day(6, 6, 0, 0)},
+		{"end of week", day(6, 10, 0, 0)},
+		{"eom", day(7, 1, 0, 0)},
+		{"this week", day(6, 3, 0, 0)},
+		{"last week", day(5, 27, 0, 0)},
+		{"next week", day(6, 10, 0, 0)},
+		{"next month", day(7, 1, 0, 0)},
+		{"wednesday", day(6, 5, 0, 0)},
+		{"next wednesday", day(6, 12, 0, 0)},
+		{"this friday", day(6, 7, 0, 0)},
+		{"mon", day(6, 10, 0, 0)},
+		{"jun 20", day(6, 20, 0, 0)},
+		{"3rd july", day(7, 3, 0, 0)},
+		{"jun 1", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"9am", day(6, 5, 9, 0)},
+		{"12am", day(6, 5, 0, 0)},
+		{"12pm", day(6, 5, 12, 0)},
+		{"noon", day(6, 5, 12, 0)},
+		{"14:30", day(6, 5, 14, 30)},
+		{"tomorrow 9am", day(6, 6, 9, 0)},
+		// "next" only skips today.
+		{"next friday at 14:30", day(6, 7, 14, 30)},
+		{"3pm friday", day(6, 7, 15, 0)},
+		{"jun 20 at 9:15am", day(6, 20, 9, 15)},
+	}
+	for _, tt := range tests {
+		got, err := parseDate(tt.value, now)
+		if err != nil {
+			t.Errorf("parseDate(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseDateErrors(t *testing.T) {
+	now := time.Date(2024, 6, 5, 10, 30, 0, 0, time.UTC)
+	for _, value := range []string{
+		"",
+		"9",          // a bare number could be a day or an hour
+		"tomorrow 9", // likewise
+		"13pm",
+		"25:00",
+		"feb 30",
+		"ju 3", // too short to tell june from july
+		"next",
+		"friday tomorrow",
+		"in two weeks",
+	} {
+		if got, err := parseDate(value, now); err == nil {
+			t.Errorf("parseDate(%q) = %v, want an error", value, got)
+		}
+	}
+}
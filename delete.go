@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+)
+
+// The delete command's flags.
+var (
+	deleteSearch string
+	deleteYes    bool
+	deleteSeries bool
+	deleteScope  string
+	deleteNotify bool
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete [EVENT-ID]",
+	Short: "Delete an event after confirmation",
+	Long: "Delete an event, found by ID or with --search by title, after asking for confirmation.\n\n" +
+		"For an occurrence of a recurring event --scope picks what goes: this occurrence (the default), the following " +
+		"ones from it on, or all of them.",
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		if deleteSeries {
+			deleteScope = events.ScopeAll
+		}
+		if err := validScope(deleteScope); err != nil {
+			usageFatalf("Invalid --scope: %v", err)
+		}
+		requireScopes(writeScope)
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		calendarID, item, err := resolveEvent(ctx, srv, id, deleteSearch)
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		scope, master, err := seriesScope(ctx, srv, calendarID, item, deleteScope)
+		if err != nil {
+			fatal("Unable to find the series", err)
+		}
+		what := "this event"
+		switch {
+		case len(item.Recurrence) > 0, master != nil && scope == events.ScopeAll:
+			what = "every occurrence of this recurring event"
+		case scope == events.ScopeFollowing:
+			what = "this and every following occurrence"
+		case item.RecurringEventId != "":
+			what = "this occurrence (use --scope following or all for more)"
+		}
+
+		fmt.Printf("%s  %s\n", eventWhen(item), item.Summary)
+		if !deleteYes {
+			ok, err := confirm("Delete " + what + "?")
+			if err != nil {
+				fatal("Unable to read confirmation", err)
+			}
+			if !ok {
+				fmt.Println("Nothing deleted.")
+				return
+			}
+		}
+		switch {
+		case scope == events.ScopeFollowing:
+			_, err = events.EndSeries(ctx, srv, calendarID, master, item.OriginalStartTime, deleteNotify, callOptions()...)
+		case master != nil:
+			err = events.Delete(ctx, srv, calendarID, master.Id, deleteNotify, callOptions()...)
+		default:
+			err = events.Delete(ctx, srv, calendarID, item.Id, deleteNotify, callOptions()...)
+		}
+		if err != nil {
+			fatal("Unable to delete event", scopeError(err))
+		}
+		fmt.Println("Deleted.")
+	},
+}
+
+func init() {
+	f := deleteCmd.Flags()
+	f.StringVar(&deleteSearch, "search", "", "find the event by title instead of ID")
+	f.BoolVarP(&deleteYes, "yes", "y", false, "do not ask for confirmation")
+	f.StringVar(&deleteScope, "scope", events.ScopeThis, "what to delete of a series: this, following or all occurrences")
+	f.BoolVar(&deleteSeries, "series", false, "delete the whole series an occurrence belongs to")
+	f.MarkDeprecated("series", "use --scope all instead")
+	f.BoolVar(&deleteNotify, "notify", true, "email the attendees about the cancellation")
+	rootCmd.AddCommand(deleteCmd)
+}
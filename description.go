@@ -0,0 +1,68 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlTagPattern tells descriptions written in Google Calendar's editor,
+// which are HTML, from plain text ones such as those of invitations sent by
+// other clients.
+var htmlTagPattern = regexp.MustCompile(`(?i)<(br|p|div|a|b|i|u|ul|ol|li|span|strong|em)\b[^>]*>`)
+
+// blankLines matches the runs of empty lines that block elements leave.
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// descriptionText renders an event description for the terminal: HTML
+// becomes plain text with line breaks, list bullets and link targets, and
+// plain text is left as it is.
+func descriptionText(s string) string {
+	s = strings.TrimSpace(s)
+	if !htmlTagPattern.MatchString(s) {
+		return s
+	}
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	// href is the target of the link being read, to be printed after its
+	// text unless that is the target itself.
+	var href, linkText string
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(blankLines.ReplaceAllString(b.String(), "\n\n"))
+		case html.TextToken:
+			text := string(z.Text())
+			b.WriteString(text)
+			linkText += text
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.Br, atom.P, atom.Div, atom.Ul, atom.Ol:
+				b.WriteString("\n")
+			case atom.Li:
+				b.WriteString("\n• ")
+			case atom.A:
+				href, linkText = "", ""
+				for _, attr := range tok.Attr {
+					if attr.Key == "href" {
+						href = attr.Val
+					}
+				}
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.P, atom.Div, atom.Ul, atom.Ol:
+				b.WriteString("\n")
+			case atom.A:
+				if href != "" && strings.TrimSpace(linkText) != href {
+					b.WriteString(" (" + href + ")")
+				}
+				href = ""
+			}
+		}
+	}
+}
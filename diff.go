@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/api/calendar/v3"
+)
+
+// snapshot is the previous fetch, kept so that diff can report what changed.
+type snapshot struct {
+	Fetched time.Time         `json:"fetched"`
+	From    time.Time         `json:"from"`
+	To      time.Time         `json:"to"`
+	Events  []*calendar.Event `json:"events"`
+}
+
+func snapshotPath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileFileName("last-events", ".json", activeProfile())), nil
+}
+
+func loadSnapshot() (*snapshot, error) {
+	path, err := snapshotPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snap := &snapshot{}
+	return snap, json.Unmarshal(b, snap)
+}
+
+func saveSnapshot(snap *snapshot) error {
+	path, err := snapshotPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// eventChange is one entry of a diff. Old is nil for additions and New is
+// nil for removals.
+type eventChange struct {
+	Kind    string          `json:"kind"`
+	ID      string          `json:"id"`
+	Summary string          `json:"summary"`
+	Old     *calendar.Event `json:"-"`
+	New     *calendar.Event `json:"-"`
+	OldTime string          `json:"old_start,omitempty"`
+	OldEnd  string          `json:"old_end,omitempty"`
+	NewTime string          `json:"new_start,omitempty"`
+	NewEnd  string          `json:"new_end,omitempty"`
+}
+
+// diffEvents matches events by ID and reports additions, removals and
+// reschedules. Only events starting inside [from, to) are compared, so that
+// a shifted window does not show up as changes.
+func diffEvents(before, after []*calendar.Event, from, to time.Time) []eventChange {
+	inWindow := func(item *calendar.Event) bool {
+		startTime, _, ok := eventTimes(item)
+		return !ok || (!startTime.Before(from) && startTime.Before(to))
+	}
+	startOf := func(item *calendar.Event) string {
+		if item.Start == nil {
+			return ""
+		}
+		if item.Start.DateTime != "" {
+			return item.Start.DateTime
+		}
+		return item.Start.Date
+	}
+	endOf := func(item *calendar.Event) string {
+		if item.End == nil {
+			return ""
+		}
+		if item.End.DateTime != "" {
+			return item.End.DateTime
+		}
+		return item.End.Date
+	}
+
+	old := map[string]*calendar.Event{}
+	for _, item := range before {
+		if inWindow(item) {
+			old[item.Id] = item
+		}
+	}
+
+	var changes []eventChange
+	seen := map[string]bool{}
+	for _, item := range after {
+		if !inWindow(item) {
+			continue
+		}
+		seen[item.Id] = true
+		prev, ok := old[item.Id]
+		switch {
+		case !ok:
+			changes = append(changes, eventChange{Kind: "added", ID: item.Id, Summary: item.Summary, New: item, NewTime: startOf(item), NewEnd: endOf(item)})
+		case startOf(prev) != startOf(item) || endOf(prev) != endOf(item):
+			changes = append(changes, eventChange{Kind: "rescheduled", ID: item.Id, Summary: item.Summary, Old: prev, New: item,
+				OldTime: startOf(prev), OldEnd: endOf(prev), NewTime: startOf(item), NewEnd: endOf(item)})
+		}
+	}
+	for _, item := range before {
+		if _, ok := old[item.Id]; ok && !seen[item.Id] {
+			changes = append(changes, eventChange{Kind: "removed", ID: item.Id, Summary: item.Summary, Old: item, OldTime: startOf(item), OldEnd: endOf(item)})
+		}
+	}
+	return changes
+}
+
+var (
+	addedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Strikethrough(true)
+	movedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+// formatEventTime renders an event's start and end for the diff report.
+func formatEventTime(item *calendar.Event) string {
+	startTime, endTime, ok := eventTimes(item)
+	if !ok {
+		return "all day"
+	}
+	return startTime.Format("Mon 15:04") + "–" + endTime.Format("15:04")
+}
+
+func writeDiff(w io.Writer, changes []eventChange, since time.Time) {
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "No changes since %s.\n", since.Format("Mon 15:04"))
+		return
+	}
+	fmt.Fprintf(w, "Changes since %s:\n", since.Format("Mon 15:04"))
+	for _, c := range changes {
+		switch c.Kind {
+		case "added":
+			fmt.Fprintln(w, addedStyle.Render(fmt.Sprintf("+ %s  %s", formatEventTime(c.New), c.Summary)))
+		case "removed":
+			fmt.Fprintln(w, removedStyle.Render(fmt.Sprintf("- %s  %s", formatEventTime(c.Old), c.Summary)))
+		case "rescheduled":
+			fmt.Fprintln(w, movedStyle.Render(fmt.Sprintf("~ %s → %s  %s", formatEventTime(c.Old), formatEventTime(c.New), c.Summary)))
+		}
+	}
+}
+
+// runDiff compares a fresh fetch of the snapshot's window against the
+// snapshot itself.
+func runDiff(ctx context.Context, srv *calendar.Service, w io.Writer, asJSON bool) error {
+	snap, err := loadSnapshot()
+	if err != nil {
+		return fmt.Errorf("no previous fetch to compare against (run a listing first): %w", err)
+	}
+	events, err := fetchEvents(ctx, srv, snap.From, snap.To)
+	if err != nil {
+		return err
+	}
+	changes := diffEvents(snap.Events, events.Items, snap.From, snap.To)
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if changes == nil {
+			changes = []eventChange{}
+		}
+		return enc.Encode(changes)
+	}
+	writeDiff(w, changes, snap.Fetched)
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The edit command's flags.
+var (
+	editSearch          string
+	editTitle           string
+	editStart           string
+	editEnd             string
+	editDuration        time.Duration
+	editLocation        string
+	editDescription     string
+	editAddAttendees    stringList
+	editRemoveAttendees stringList
+	editColor           string
+	editScope           string
+	editNotify          bool
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit [EVENT-ID]",
+	Short: "Change an event's title, time, place, guests or color",
+	Long: "Change only the given fields of an event, found by ID or with --search by title.\n\n" +
+		"Moving the start keeps the event's length unless --end or --duration is given too.\n\n" +
+		"For an occurrence of a recurring event --scope picks what changes: this occurrence (the default), the " +
+		"following ones from it on, which then become a series of their own, or all of them. Moving all of them " +
+		"shifts the whole series by as much as the occurrence moves.",
+	Example: `  go-gcal-cli edit abc123 --start "friday 15:00" --add-attendee b@example.com
+  go-gcal-cli edit --search standup --title "Daily standup" --notify=false
+  go-gcal-cli edit abc123 --color tomato
+  go-gcal-cli edit --search standup --start "monday 9:30" --scope following`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		if err := validScope(editScope); err != nil {
+			usageFatalf("Invalid --scope: %v", err)
+		}
+		requireScopes(writeScope)
+		requireContacts(editAddAttendees)
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		calendarID, item, err := resolveEvent(ctx, srv, id, editSearch)
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		if editAddAttendees, err = resolveAttendees(ctx, editAddAttendees); err != nil {
+			fatal("Unable to find guest", err)
+		}
+		patch, err := editPatch(cmd, item, time.Now())
+		if err != nil {
+			fatal("Invalid change", &UsageError{err})
+		}
+		scope, master, err := seriesScope(ctx, srv, calendarID, item, editScope)
+		if err != nil {
+			fatal("Unable to find the series", err)
+		}
+		var updated *calendar.Event
+		switch {
+		case scope == events.ScopeFollowing:
+			updated, err = events.SplitSeries(ctx, srv, calendarID, master, item, patch, editNotify, callOptions()...)
+		case master != nil:
+			if patch, err = seriesPatch(patch, master, item); err != nil {
+				fatal("Invalid change", &UsageError{err})
+			}
+			updated, err = events.Patch(ctx, srv, calendarID, master.Id, patch, editNotify, callOptions()...)
+		default:
+			updated, err = events.Patch(ctx, srv, calendarID, item.Id, patch, editNotify, callOptions()...)
+		}
+		if err != nil {
+			fatal("Unable to update event", scopeError(err))
+		}
+		fmt.Printf("Updated %s  %s\n", eventWhen(updated), updated.Summary)
+	},
+}
+
+// editPatch builds a patch holding just the fields whose flags were given.
+func editPatch(cmd *cobra.Command, item *calendar.Event, now time.Time) (*calendar.Event, error) {
+	changed := cmd.Flags().Changed
+	patch := &calendar.Event{}
+	if changed("title") {
+		patch.Summary = editTitle
+	}
+	if changed("color") {
+		id, err := eventColorID(editColor)
+		if err != nil {
+			return nil, err
+		}
+		patch.ColorId = id
+		patch.ForceSendFields = append(patch.ForceSendFields, "ColorId")
+	}
+	if changed("location") {
+		patch.Location = editLocation
+		patch.ForceSendFields = append(patch.ForceSendFields, "Location")
+	}
+	if changed("description") {
+		patch.Description = editDescription
+		patch.ForceSendFields = append(patch.ForceSendFields, "Description")
+	}
+
+	if changed("start") || changed("end") || changed("duration") {
+		start, end, ok := eventTimes(item)
+		if !ok {
+			return nil, fmt.Errorf("changing the time of all-day events is not supported")
+		}
+		length := end.Sub(start)
+		var err error
+		if changed("start") {
+			if start, err = parseDate(editStart, now); err != nil {
+				return nil, fmt.Errorf("--start: %w", err)
+			}
+		}
+		switch {
+		case changed("end"):
+			if end, err = parseDate(editEnd, now); err != nil {
+				return nil, fmt.Errorf("--end: %w", err)
+			}
+		case changed("duration"):
+			end = start.Add(editDuration)
+		default:
+			end = start.Add(length)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("the event must end after it starts")
+		}
+		patch.Start = &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: item.Start.TimeZone}
+		patch.End = &calendar.EventDateTime{DateTime: end.Format(time.RFC3339), TimeZone: item.End.TimeZone}
+	}
+
+	if len(editAddAttendees) > 0 || len(editRemoveAttendees) > 0 {
+		patch.Attendees, _ = changeAttendees(item.Attendees, editAddAttendees, editRemoveAttendees, false)
+		patch.ForceSendFields = append(patch.ForceSendFields, "Attendees")
+	}
+	return patch, nil
+}
+
+func init() {
+	f := editCmd.Flags()
+	f.StringVar(&editSearch, "search", "", "find the event by title instead of ID")
+	f.StringVar(&editTitle, "title", "", "new title")
+	f.StringVar(&editStart, "start", "", "new start time (e.g. \"friday 3pm\", \"in 2 days\")")
+	f.StringVar(&editEnd, "end", "", "new end time")
+	f.DurationVar(&editDuration, "duration", 0, "new length, counted from the (new) start")
+	f.StringVar(&editLocation, "location", "", "new location; empty to clear it")
+	f.StringVar(&editDescription, "description", "", "new description; empty to clear it")
+	f.Var(&editAddAttendees, "add-attendee", "email address or contact name to invite; repeatable")
+	f.Var(&editRemoveAttendees, "remove-attendee", "email address to uninvite; repeatable")
+	f.StringVar(&editColor, "color", "", "new color, e.g. tomato; none for the calendar's color")
+	f.StringVar(&editScope, "scope", events.ScopeThis, "what to change of a series: this, following or all occurrences")
+	f.BoolVar(&editNotify, "notify", true, "email the attendees about the change")
+	rootCmd.AddCommand(editCmd)
+}
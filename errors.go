@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// Exit codes, so that scripts can tell failures apart.
+const (
+	exitFailure  = 1
+	exitUsage    = 2
+	exitAuth     = 3
+	exitNotFound = 4
+	exitQuota    = 5
+	exitNetwork  = 6
+)
+
+// UsageError means a flag, argument or setting has a value the command
+// cannot use.
+type UsageError struct{ Err error }
+
+// AuthError means the credentials were missing, expired, revoked or lacked
+// a scope the call needed.
+type AuthError struct{ Err error }
+
+// QuotaError means the API's rate limits refused the call even after
+// retrying.
+type QuotaError struct{ Err error }
+
+// NetworkError means the API could not be reached.
+type NetworkError struct{ Err error }
+
+// NotFoundError means a calendar or event does not exist or is not visible
+// to the user.
+type NotFoundError struct{ Err error }
+
+func (e *UsageError) Error() string    { return e.Err.Error() }
+func (e *UsageError) Unwrap() error    { return e.Err }
+func (e *AuthError) Error() string     { return e.Err.Error() }
+func (e *AuthError) Unwrap() error     { return e.Err }
+func (e *QuotaError) Error() string    { return e.Err.Error() }
+func (e *QuotaError) Unwrap() error    { return e.Err }
+func (e *NetworkError) Error() string  { return e.Err.Error() }
+func (e *NetworkError) Unwrap() error  { return e.Err }
+func (e *NotFoundError) Error() string { return e.Err.Error() }
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// classifyError wraps err in the error type matching its cause, leaving
+// errors of no known kind as they are.
+func classifyError(err error) error {
+	var (
+		usageErr    *UsageError
+		authErr     *AuthError
+		quotaErr    *QuotaError
+		networkErr  *NetworkError
+		notFoundErr *NotFoundError
+		retrieveErr *oauth2.RetrieveError
+		gerr        *googleapi.Error
+	)
+	switch {
+	case errors.As(err, &usageErr), errors.As(err, &authErr), errors.As(err, &quotaErr), errors.As(err, &networkErr), errors.As(err, &notFoundErr):
+		return err
+	case errors.Is(err, errInsufficientScope), errors.As(err, &retrieveErr):
+		return &AuthError{err}
+	case errors.Is(err, errNoCalendar):
+		return &NotFoundError{err}
+	case errors.As(err, &gerr):
+		switch {
+		case gerr.Code == http.StatusUnauthorized:
+			return &AuthError{err}
+		case gerr.Code == http.StatusTooManyRequests, gerr.Code == http.StatusForbidden && rateLimited(gerr):
+			return &QuotaError{err}
+		case gerr.Code == http.StatusNotFound, gerr.Code == http.StatusGone:
+			return &NotFoundError{err}
+		}
+	case isNetworkError(err):
+		return &NetworkError{err}
+	}
+	return err
+}
+
+// rateLimited reports whether a 403 is the API's rate limit rather than a
+// refusal.
+func rateLimited(gerr *googleapi.Error) bool {
+	for _, item := range gerr.Errors {
+		if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// errorHint suggests how to get past err, and picks the exit code for it.
+func errorHint(err error) (string, int) {
+	var (
+		usageErr    *UsageError
+		authErr     *AuthError
+		quotaErr    *QuotaError
+		networkErr  *NetworkError
+		notFoundErr *NotFoundError
+	)
+	switch {
+	case errors.As(err, &usageErr):
+		return "", exitUsage
+	case errors.Is(err, errInsufficientScope):
+		// scopeError already says how to re-authorize.
+		return "", exitAuth
+	case errors.As(err, &authErr):
+		return "run \"go-gcal-cli auth login\" to authorize again", exitAuth
+	case errors.As(err, &quotaErr):
+		return "the Calendar API's rate limit was hit; wait a minute or raise --max-attempts", exitQuota
+	case errors.As(err, &networkErr):
+		return "check your network connection; --offline shows the cached events", exitNetwork
+	case errors.As(err, &notFoundErr):
+		return "check the calendar or event ID; \"go-gcal-cli calendars\" lists your calendars", exitNotFound
+	}
+	return "", exitFailure
+}
+
+// usageFatalf reports a bad flag or argument and exits with exitUsage, as
+// cobra does for flags that do not parse.
+func usageFatalf(format string, args ...any) {
+	log.Printf(format, args...)
+	os.Exit(exitUsage)
+}
+
+// fatal reports a failed action, with a hint for fixing it where the cause
+// is known, and exits with the code for that cause.
+func fatal(action string, err error) {
+	err = classifyError(err)
+	hint, code := errorHint(err)
+	log.Printf("%s: %v", action, err)
+	if hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,213 @@
+// Package events builds and changes Google Calendar events on behalf of the
+// commands that write to a calendar.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// Spec describes an event to create.
+type Spec struct {
+	Title       string
+	Start       time.Time
+	Duration    time.Duration
+	AllDay      bool // Start's date, lasting Duration rounded up to whole days
+	TimeZone    string
+	Location    string
+	Description string
+	Attendees   []string
+	Meet        bool     // ask for a Google Meet conference
+	Recurrence  []string // RRULE and other RFC 5545 lines; needs TimeZone
+}
+
+// Event converts s into the API's representation.
+func (s Spec) Event() (*calendar.Event, error) {
+	if s.Title == "" {
+		return nil, errors.New("an event needs a title")
+	}
+	if s.Duration <= 0 {
+		return nil, errors.New("an event needs a positive duration")
+	}
+	if len(s.Recurrence) > 0 && !s.AllDay && s.TimeZone == "" {
+		return nil, errors.New("a recurring event needs a time zone")
+	}
+	item := &calendar.Event{
+		Summary:     s.Title,
+		Location:    s.Location,
+		Description: s.Description,
+		Recurrence:  s.Recurrence,
+	}
+	if s.AllDay {
+		days := int((s.Duration + 24*time.Hour - 1) / (24 * time.Hour))
+		item.Start = &calendar.EventDateTime{Date: s.Start.Format("2006-01-02")}
+		item.End = &calendar.EventDateTime{Date: s.Start.AddDate(0, 0, days).Format("2006-01-02")}
+	} else {
+		item.Start = &calendar.EventDateTime{DateTime: s.Start.Format(time.RFC3339), TimeZone: s.TimeZone}
+		item.End = &calendar.EventDateTime{DateTime: s.Start.Add(s.Duration).Format(time.RFC3339), TimeZone: s.TimeZone}
+	}
+	for _, email := range s.Attendees {
+		item.Attendees = append(item.Attendees, &calendar.EventAttendee{Email: email})
+	}
+	if s.Meet {
+		id, err := requestID()
+		if err != nil {
+			return nil, err
+		}
+		item.ConferenceData = &calendar.ConferenceData{
+			CreateRequest: &calendar.CreateConferenceRequest{
+				RequestId:             id,
+				ConferenceSolutionKey: &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"},
+			},
+		}
+	}
+	return item, nil
+}
+
+// Copy returns a new event with item's title, place, description, guests,
+// color and reminders, for the caller to give a time. A Google Meet call is
+// requested afresh, so the copy gets its own link; other conferences, such
+// as those of add-ons, are copied as they are. The guests' answers are not.
+func Copy(item *calendar.Event) (*calendar.Event, error) {
+	dup := &calendar.Event{
+		Summary:      item.Summary,
+		Location:     item.Location,
+		Description:  item.Description,
+		ColorId:      item.ColorId,
+		Visibility:   item.Visibility,
+		Transparency: item.Transparency,
+		Reminders:    item.Reminders,
+	}
+	for _, a := range item.Attendees {
+		if a.Self {
+			continue
+		}
+		dup.Attendees = append(dup.Attendees, &calendar.EventAttendee{
+			Email:       a.Email,
+			DisplayName: a.DisplayName,
+			Optional:    a.Optional,
+			Resource:    a.Resource,
+		})
+	}
+	if conf := item.ConferenceData; conf != nil {
+		if conf.ConferenceSolution != nil && conf.ConferenceSolution.Key != nil && conf.ConferenceSolution.Key.Type == "hangoutsMeet" {
+			id, err := requestID()
+			if err != nil {
+				return nil, err
+			}
+			dup.ConferenceData = &calendar.ConferenceData{
+				CreateRequest: &calendar.CreateConferenceRequest{
+					RequestId:             id,
+					ConferenceSolutionKey: &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"},
+				},
+			}
+		} else if len(conf.EntryPoints) > 0 {
+			dup.ConferenceData = &calendar.ConferenceData{
+				ConferenceId:       conf.ConferenceId,
+				ConferenceSolution: conf.ConferenceSolution,
+				EntryPoints:        conf.EntryPoints,
+				Notes:              conf.Notes,
+				Parameters:         conf.Parameters,
+			}
+		}
+	}
+	return dup, nil
+}
+
+// requestID returns a random idempotency key for a conference request.
+func requestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Insert creates item on the calendar. Conference requests are honored and
+// attendees are sent invitations.
+func Insert(ctx context.Context, srv *calendar.Service, calendarID string, item *calendar.Event, opts ...googleapi.CallOption) (*calendar.Event, error) {
+	call := srv.Events.Insert(calendarID, item).Context(ctx)
+	if item.ConferenceData != nil {
+		call = call.ConferenceDataVersion(1)
+	}
+	if len(item.Attendees) > 0 {
+		call = call.SendUpdates("all")
+	}
+	return call.Do(opts...)
+}
+
+// QuickAdd creates an event from a sentence such as "Lunch with Sam
+// tomorrow at noon", letting Google parse the time and title.
+func QuickAdd(ctx context.Context, srv *calendar.Service, calendarID, text string, opts ...googleapi.CallOption) (*calendar.Event, error) {
+	return srv.Events.QuickAdd(calendarID, text).Context(ctx).Do(opts...)
+}
+
+// Delete removes an event, or a whole series when eventID is a recurring
+// event's master. Attendees are told when notify is set.
+func Delete(ctx context.Context, srv *calendar.Service, calendarID, eventID string, notify bool, opts ...googleapi.CallOption) error {
+	return srv.Events.Delete(calendarID, eventID).SendUpdates(sendUpdates(notify)).Context(ctx).Do(opts...)
+}
+
+// Move moves an event, or a whole series given its master, to another
+// calendar, keeping its ID. Attendees are told when notify is set.
+func Move(ctx context.Context, srv *calendar.Service, calendarID, eventID, destination string, notify bool, opts ...googleapi.CallOption) (*calendar.Event, error) {
+	return srv.Events.Move(calendarID, eventID, destination).SendUpdates(sendUpdates(notify)).Context(ctx).Do(opts...)
+}
+
+func sendUpdates(notify bool) string {
+	if notify {
+		return "all"
+	}
+	return "none"
+}
+
+// Patch changes only the fields set in patch. Lists such as attendees are
+// replaced as a whole, so patch must carry the complete new list. A
+// conference request in patch is honored.
+func Patch(ctx context.Context, srv *calendar.Service, calendarID, eventID string, patch *calendar.Event, notify bool, opts ...googleapi.CallOption) (*calendar.Event, error) {
+	call := srv.Events.Patch(calendarID, eventID, patch).SendUpdates(sendUpdates(notify)).Context(ctx)
+	if patch.ConferenceData != nil {
+		call = call.ConferenceDataVersion(1)
+	}
+	return call.Do(opts...)
+}
+
+// Responses are the attendee response statuses users can set.
+var Responses = map[string]string{
+	"accept":    "accepted",
+	"decline":   "declined",
+	"tentative": "tentative",
+}
+
+// ErrNotInvited is returned by RSVP for events the user is not a guest of.
+var ErrNotInvited = errors.New("you are not on the guest list of this event")
+
+// RSVP sets the user's response to item, with an optional note to the
+// organizer. response is one of the keys of Responses.
+func RSVP(ctx context.Context, srv *calendar.Service, calendarID string, item *calendar.Event, response, comment string, opts ...googleapi.CallOption) (*calendar.Event, error) {
+	status, ok := Responses[response]
+	if !ok {
+		return nil, fmt.Errorf("unknown response %q; use accept, decline or tentative", response)
+	}
+	var self *calendar.EventAttendee
+	for _, a := range item.Attendees {
+		if a.Self {
+			self = a
+		}
+	}
+	if self == nil {
+		return nil, ErrNotInvited
+	}
+	self.ResponseStatus = status
+	self.Comment = comment
+	// Only the organizer hears about a response, so always tell them.
+	patch := &calendar.Event{Attendees: item.Attendees}
+	return Patch(ctx, srv, calendarID, item.Id, patch, true, opts...)
+}
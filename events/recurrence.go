@@ -0,0 +1,188 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// The parts of a series an edit or deletion of an occurrence can apply to,
+// as the web UI offers them.
+const (
+	ScopeThis      = "this"
+	ScopeFollowing = "following"
+	ScopeAll       = "all"
+)
+
+// Scopes are the accepted --scope values.
+var Scopes = []string{ScopeThis, ScopeFollowing, ScopeAll}
+
+// untilValue formats the last moment before t as an RRULE UNTIL: a date for
+// all-day series, a UTC date-time otherwise.
+func untilValue(t time.Time, allDay bool) string {
+	if allDay {
+		return t.AddDate(0, 0, -1).Format("20060102")
+	}
+	return t.Add(-time.Second).UTC().Format("20060102T150405Z")
+}
+
+// rewriteRRules applies f to the parts of each RRULE line in lines, leaving
+// the other lines alone.
+func rewriteRRules(lines []string, f func(parts []string) []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if rule, ok := strings.CutPrefix(line, "RRULE:"); ok {
+			line = "RRULE:" + strings.Join(f(strings.Split(rule, ";")), ";")
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// endRules ends the recurrence before t, replacing any COUNT or UNTIL.
+func endRules(lines []string, t time.Time, allDay bool) []string {
+	return rewriteRRules(lines, func(parts []string) []string {
+		var kept []string
+		for _, part := range parts {
+			if !strings.HasPrefix(part, "COUNT=") && !strings.HasPrefix(part, "UNTIL=") {
+				kept = append(kept, part)
+			}
+		}
+		return append(kept, "UNTIL="+untilValue(t, allDay))
+	})
+}
+
+// restRules lowers any COUNT by the done occurrences before the split, for
+// the series that carries on from there.
+func restRules(lines []string, done int) []string {
+	return rewriteRRules(lines, func(parts []string) []string {
+		for i, part := range parts {
+			if count, ok := strings.CutPrefix(part, "COUNT="); ok {
+				if n, err := strconv.Atoi(count); err == nil {
+					parts[i] = "COUNT=" + strconv.Itoa(max(1, n-done))
+				}
+			}
+		}
+		return parts
+	})
+}
+
+// hasCount reports whether any RRULE in lines ends after a number of
+// occurrences.
+func hasCount(lines []string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, "RRULE:") && strings.Contains(line, "COUNT=") {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTime returns when dt is, midnight UTC for dates.
+func eventTime(dt *calendar.EventDateTime) (time.Time, bool, error) {
+	if dt.Date != "" {
+		t, err := time.Parse("2006-01-02", dt.Date)
+		return t, true, err
+	}
+	t, err := time.Parse(time.RFC3339, dt.DateTime)
+	return t, false, err
+}
+
+// merge overlays the fields set in patch, as the API's patch semantics
+// would, onto a copy of item.
+func merge(item, patch *calendar.Event) (*calendar.Event, error) {
+	base, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, err
+	}
+	over, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(over, &fields); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	merged := &calendar.Event{}
+	return merged, json.Unmarshal(b, merged)
+}
+
+// EndSeries stops the series master before from, so that only the
+// occurrences before it remain.
+func EndSeries(ctx context.Context, srv *calendar.Service, calendarID string, master *calendar.Event, from *calendar.EventDateTime, notify bool, opts ...googleapi.CallOption) (*calendar.Event, error) {
+	t, allDay, err := eventTime(from)
+	if err != nil {
+		return nil, fmt.Errorf("occurrence start: %w", err)
+	}
+	patch := &calendar.Event{Recurrence: endRules(master.Recurrence, t, allDay)}
+	return Patch(ctx, srv, calendarID, master.Id, patch, notify, opts...)
+}
+
+// SplitSeries applies patch to occurrence and every later one of the series
+// master: a new series with the change carries on from occurrence, and
+// master is ended before it. The new series is returned.
+func SplitSeries(ctx context.Context, srv *calendar.Service, calendarID string, master, occurrence, patch *calendar.Event, notify bool, opts ...googleapi.CallOption) (*calendar.Event, error) {
+	done := 0
+	if hasCount(master.Recurrence) {
+		// COUNT includes the occurrences that were cancelled.
+		until := occurrence.OriginalStartTime.DateTime
+		if occurrence.OriginalStartTime.Date != "" {
+			until = occurrence.OriginalStartTime.Date + "T00:00:00Z"
+		}
+		call := srv.Events.Instances(calendarID, master.Id).ShowDeleted(true).TimeMax(until).Context(ctx)
+		for {
+			page, err := call.Do(opts...)
+			if err != nil {
+				return nil, err
+			}
+			done += len(page.Items)
+			if page.NextPageToken == "" {
+				break
+			}
+			call = call.PageToken(page.NextPageToken)
+		}
+	}
+	rest := &calendar.Event{
+		Summary:                 master.Summary,
+		Description:             master.Description,
+		Location:                master.Location,
+		ColorId:                 master.ColorId,
+		Attendees:               master.Attendees,
+		Reminders:               master.Reminders,
+		Transparency:            master.Transparency,
+		Visibility:              master.Visibility,
+		GuestsCanModify:         master.GuestsCanModify,
+		GuestsCanInviteOthers:   master.GuestsCanInviteOthers,
+		GuestsCanSeeOtherGuests: master.GuestsCanSeeOtherGuests,
+		Start:                   occurrence.Start,
+		End:                     occurrence.End,
+		Recurrence:              restRules(master.Recurrence, done),
+	}
+	rest, err := merge(rest, patch)
+	if err != nil {
+		return nil, err
+	}
+	created, err := srv.Events.Insert(calendarID, rest).SendUpdates(sendUpdates(notify)).Context(ctx).Do(opts...)
+	if err != nil {
+		return nil, err
+	}
+	// The change is in place by now; failing to end the old series leaves
+	// both running, which the error says.
+	if _, err := EndSeries(ctx, srv, calendarID, master, occurrence.OriginalStartTime, notify, opts...); err != nil {
+		return created, fmt.Errorf("the new series was created but the old one could not be ended: %w", err)
+	}
+	return created, nil
+}
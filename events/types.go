@@ -0,0 +1,87 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// AutoDecline maps the --decline values of the ooo and focus commands to
+// the API's auto-decline modes.
+var AutoDecline = map[string]string{
+	"none": "declineNone",
+	"all":  "declineAllConflictingInvitations",
+	"new":  "declineOnlyNewConflictingInvitations",
+}
+
+// Block describes an out-of-office or focus time event to create. Both
+// only exist on primary calendars and must have times, not just dates.
+type Block struct {
+	Title    string
+	Start    time.Time
+	End      time.Time
+	TimeZone string
+	Decline  string // a key of AutoDecline
+	Message  string // sent with the automatic declines
+}
+
+// times validates b and returns its start and end.
+func (b Block) times() (*calendar.EventDateTime, *calendar.EventDateTime, string, error) {
+	if !b.End.After(b.Start) {
+		return nil, nil, "", errors.New("the end must be after the start")
+	}
+	mode, ok := AutoDecline[b.Decline]
+	if !ok {
+		return nil, nil, "", fmt.Errorf("unknown decline mode %q; use none, all or new", b.Decline)
+	}
+	if mode == "declineNone" && b.Message != "" {
+		return nil, nil, "", errors.New("a decline message needs a decline mode other than none")
+	}
+	start := &calendar.EventDateTime{DateTime: b.Start.Format(time.RFC3339), TimeZone: b.TimeZone}
+	end := &calendar.EventDateTime{DateTime: b.End.Format(time.RFC3339), TimeZone: b.TimeZone}
+	return start, end, mode, nil
+}
+
+// OutOfOffice converts b into an out-of-office event.
+func (b Block) OutOfOffice() (*calendar.Event, error) {
+	start, end, mode, err := b.times()
+	if err != nil {
+		return nil, err
+	}
+	return &calendar.Event{
+		Summary:   b.Title,
+		EventType: "outOfOffice",
+		Start:     start,
+		End:       end,
+		OutOfOfficeProperties: &calendar.EventOutOfOfficeProperties{
+			AutoDeclineMode: mode,
+			DeclineMessage:  b.Message,
+		},
+	}, nil
+}
+
+// FocusTime converts b into a focus time event, muting chat notifications
+// when doNotDisturb is set.
+func (b Block) FocusTime(doNotDisturb bool) (*calendar.Event, error) {
+	start, end, mode, err := b.times()
+	if err != nil {
+		return nil, err
+	}
+	chat := "available"
+	if doNotDisturb {
+		chat = "doNotDisturb"
+	}
+	return &calendar.Event{
+		Summary:   b.Title,
+		EventType: "focusTime",
+		Start:     start,
+		End:       end,
+		FocusTimeProperties: &calendar.EventFocusTimeProperties{
+			AutoDeclineMode: mode,
+			DeclineMessage:  b.Message,
+			ChatStatus:      chat,
+		},
+	}, nil
+}
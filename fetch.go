@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// callOptions returns the per-request options shared by every API call.
+func callOptions() []googleapi.CallOption {
+	var opts []googleapi.CallOption
+	if *quotaUser != "" {
+		opts = append(opts, googleapi.QuotaUser(*quotaUser))
+	}
+	return opts
+}
+
+// requestContext bounds the API calls of one command by --timeout.
+func requestContext() (context.Context, context.CancelFunc) {
+	if *timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), *timeout)
+}
+
+// calendarIDs returns the calendars selected with --all-calendars or
+// --calendar, falling back to the active profile's default calendar and then
+// to primary.
+func calendarIDs() []string {
+	if *allCalendars && len(calendarList) > 0 {
+		var ids []string
+		for _, entry := range calendarList {
+			if !entry.Hidden {
+				ids = append(ids, entry.Id)
+			}
+		}
+		return ids
+	}
+	if len(calendarFlags) > 0 {
+		return calendarFlags
+	}
+	if _, p, err := currentProfile(); err == nil && p.Calendar != "" {
+		return []string{p.Calendar}
+	}
+	return []string{"primary"}
+}
+
+// eventCalendars remembers which calendar each fetched event came from, as
+// the API's Event has no field for it. It is keyed by the event's address so
+// as not to keep the event alive; a finalizer drops the entry once the event
+// is garbage, or watch, the daemon and the TUI would hold on to every poll's
+// events.
+var eventCalendars sync.Map
+
+// rememberCalendar records that item was fetched from the calendar id.
+func rememberCalendar(item *calendar.Event, id string) {
+	if _, loaded := eventCalendars.Swap(uintptr(unsafe.Pointer(item)), id); !loaded {
+		runtime.SetFinalizer(item, forgetCalendar)
+	}
+}
+
+func forgetCalendar(item *calendar.Event) {
+	eventCalendars.Delete(uintptr(unsafe.Pointer(item)))
+}
+
+// eventCalendar returns the calendar ID item was fetched from.
+func eventCalendar(item *calendar.Event) string {
+	if id, ok := eventCalendars.Load(uintptr(unsafe.Pointer(item))); ok {
+		return id.(string)
+	}
+	return ""
+}
+
+// fetchAll runs fetch for every calendar concurrently, --parallel at a time,
+// and merges the results in start order. The first error cancels the
+// remaining fetches.
+func fetchAll(ctx context.Context, ids []string, fetch func(ctx context.Context, id string) ([]*calendar.Event, error)) (*calendar.Events, error) {
+	results := make([][]*calendar.Event, len(ids))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(*parallel)
+	for i, id := range ids {
+		g.Go(func() error {
+			items, err := fetch(ctx, id)
+			for _, item := range items {
+				rememberCalendar(item, id)
+			}
+			results[i] = items
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, scopeError(err)
+	}
+
+	events := &calendar.Events{}
+	for _, items := range results {
+		events.Items = append(events.Items, items...)
+	}
+	sortByStart(events.Items)
+	return events, nil
+}
+
+// eventStart returns when an event begins, treating all-day events as
+// starting at local midnight.
+func eventStart(item *calendar.Event) time.Time {
+	if startTime, _, ok := eventTimes(item); ok {
+		return startTime
+	}
+	if item.Start != nil && item.Start.Date != "" {
+		t, _ := time.ParseInLocation("2006-01-02", item.Start.Date, time.Local)
+		return t
+	}
+	return time.Time{}
+}
+
+// eventEnd returns when an event ends, treating all-day events as ending at
+// local midnight after their last day.
+func eventEnd(item *calendar.Event) time.Time {
+	if _, endTime, ok := eventTimes(item); ok {
+		return endTime
+	}
+	if item.End != nil && item.End.Date != "" {
+		t, _ := time.ParseInLocation("2006-01-02", item.End.Date, time.Local)
+		return t
+	}
+	return time.Time{}
+}
+
+// sortByStart orders events chronologically, keeping the API order for ties.
+func sortByStart(items []*calendar.Event) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventStart(items[i]).Before(eventStart(items[j]))
+	})
+}
+
+// fetchEvents lists the selected calendars' events between from and to,
+// from the event cache where it covers the window.
+func fetchEvents(ctx context.Context, srv *calendar.Service, from, to time.Time) (*calendar.Events, error) {
+	t := from.Format(time.RFC3339)
+
+	tMax := to.Format(time.RFC3339)
+	if err := loadCalendars(ctx, srv); err != nil {
+		return nil, err
+	}
+	cached := useEventCache(from)
+	if *offline && !cached {
+		return nil, fmt.Errorf("--offline only covers windows from %d days ago onwards, without --no-single-events", eventCacheDays)
+	}
+	return fetchAll(ctx, calendarIDs(), func(ctx context.Context, id string) ([]*calendar.Event, error) {
+		if cached {
+			cache, err := syncEvents(ctx, srv, id)
+			if err != nil {
+				return nil, err
+			}
+			return cache.between(from, to), nil
+		}
+		call := srv.Events.List(id).ShowDeleted(false).SingleEvents(!*noSingle).TimeMin(t).TimeMax(tMax).Context(ctx)
+		if !*noSingle {
+			// The API only supports startTime ordering for expanded instances.
+			call = call.OrderBy("startTime")
+		}
+		var items []*calendar.Event
+		for {
+			page, err := call.Do(callOptions()...)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, page.Items...)
+			if page.NextPageToken == "" {
+				return items, nil
+			}
+			call = call.PageToken(page.NextPageToken)
+		}
+	})
+}
+
+// fetchUpdated lists the selected calendars' events changed since since,
+// with each recurring series once.
+func fetchUpdated(ctx context.Context, srv *calendar.Service, since time.Time) (*calendar.Events, error) {
+	if *offline {
+		return nil, errors.New("changes cannot be listed with --offline")
+	}
+	return fetchAll(ctx, calendarIDs(), func(ctx context.Context, id string) ([]*calendar.Event, error) {
+		var items []*calendar.Event
+		call := srv.Events.List(id).ShowDeleted(false).UpdatedMin(since.Format(time.RFC3339)).Context(ctx)
+		for {
+			page, err := call.Do(callOptions()...)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, page.Items...)
+			if page.NextPageToken == "" {
+				return items, nil
+			}
+			call = call.PageToken(page.NextPageToken)
+		}
+	})
+}
+
+// fetchMatching lists the events between from and to that the API's
+// full-text search finds for q, expanding recurring events.
+func fetchMatching(ctx context.Context, srv *calendar.Service, q string, from, to time.Time) (*calendar.Events, error) {
+	if *offline {
+		return nil, errors.New("events cannot be searched with --offline")
+	}
+	if err := loadCalendars(ctx, srv); err != nil {
+		return nil, err
+	}
+	return fetchAll(ctx, calendarIDs(), func(ctx context.Context, id string) ([]*calendar.Event, error) {
+		var items []*calendar.Event
+		call := srv.Events.List(id).Q(q).SingleEvents(true).OrderBy("startTime").
+			TimeMin(from.Format(time.RFC3339)).TimeMax(to.Format(time.RFC3339)).MaxResults(2500).Context(ctx)
+		for {
+			page, err := call.Do(callOptions()...)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, page.Items...)
+			if page.NextPageToken == "" {
+				return items, nil
+			}
+			call = call.PageToken(page.NextPageToken)
+		}
+	})
+}
+
+// The commands about the meeting on now or next fetch upcomingBatch events
+// from now on at first, and twice as many each time none of them qualifies,
+// up to upcomingMax.
+const (
+	upcomingBatch = 5
+	upcomingMax   = 640
+)
+
+// findUpcoming fetches the events from now on in growing batches until pick
+// finds one among them, they run out or upcomingMax is reached. It returns
+// what pick found, or nil, and the events fetched last.
+func findUpcoming(ctx context.Context, srv *calendar.Service, now time.Time, pick func(items []*calendar.Event) *calendar.Event) (*calendar.Event, *calendar.Events, error) {
+	fetched := -1
+	for n := upcomingBatch; ; n *= 2 {
+		events, err := fetchUpcoming(ctx, srv, now, n)
+		if err != nil {
+			return nil, nil, err
+		}
+		if item := pick(events.Items); item != nil || len(events.Items) == fetched || n >= upcomingMax {
+			return item, events, nil
+		}
+		fetched = len(events.Items)
+	}
+}
+
+// fetchUpcoming pages forward from now until each calendar has yielded n
+// timed events or runs out. The event cache has them all at hand.
+func fetchUpcoming(ctx context.Context, srv *calendar.Service, now time.Time, n int) (*calendar.Events, error) {
+	if err := loadCalendars(ctx, srv); err != nil {
+		return nil, err
+	}
+	cached := useEventCache(now)
+	return fetchAll(ctx, calendarIDs(), func(ctx context.Context, id string) ([]*calendar.Event, error) {
+		if cached {
+			cache, err := syncEvents(ctx, srv, id)
+			if err != nil {
+				return nil, err
+			}
+			items := cache.between(now, time.Time{})
+			found := 0
+			for i, item := range items {
+				if startTime, endTime, ok := eventTimes(item); ok && endTime.After(startTime) {
+					if found++; found == n {
+						return items[:i+1], nil
+					}
+				}
+			}
+			return items, nil
+		}
+		var items []*calendar.Event
+		found := 0
+		call := srv.Events.List(id).ShowDeleted(false).SingleEvents(true).OrderBy("startTime").TimeMin(now.Format(time.RFC3339)).Context(ctx)
+		for {
+			page, err := call.Do(callOptions()...)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range page.Items {
+				if startTime, endTime, ok := eventTimes(item); ok && endTime.After(startTime) {
+					found++
+				}
+			}
+			items = append(items, page.Items...)
+			if found >= n || page.NextPageToken == "" {
+				return items, nil
+			}
+			call = call.PageToken(page.NextPageToken)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// period is a span of time, busy or free.
+type period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// String renders p as "Mon 2006-01-02 15:04–16:00", repeating the date when
+// p ends on another day.
+func (p period) String() string {
+	start, end := p.Start.Local(), p.End.Local()
+	layout := "15:04"
+	if !startOfDay(start).Equal(startOfDay(end)) {
+		layout = "Mon 2006-01-02 15:04"
+	}
+	return start.Format("Mon 2006-01-02 15:04") + "–" + end.Format(layout)
+}
+
+// busyTimes is one person's or calendar's answer to a free/busy query. Err
+// is set when their calendar could not be read, e.g. because it is not
+// shared.
+type busyTimes struct {
+	ID   string   `json:"id"`
+	Busy []period `json:"busy"`
+	Err  string   `json:"error,omitempty"`
+}
+
+// queryFreeBusy asks when each of ids, email addresses or calendar IDs, is
+// busy between from and to, in the order given.
+func queryFreeBusy(ctx context.Context, srv *calendar.Service, ids []string, from, to time.Time) ([]busyTimes, error) {
+	req := &calendar.FreeBusyRequest{
+		TimeMin: from.Format(time.RFC3339),
+		TimeMax: to.Format(time.RFC3339),
+	}
+	for _, id := range ids {
+		req.Items = append(req.Items, &calendar.FreeBusyRequestItem{Id: id})
+	}
+	resp, err := srv.Freebusy.Query(req).Context(ctx).Do(callOptions()...)
+	if err != nil {
+		return nil, scopeError(err)
+	}
+
+	var result []busyTimes
+	for _, id := range ids {
+		b := busyTimes{ID: id}
+		cal, ok := resp.Calendars[id]
+		if !ok {
+			b.Err = "not found"
+			result = append(result, b)
+			continue
+		}
+		var reasons []string
+		for _, e := range cal.Errors {
+			reasons = append(reasons, e.Reason)
+		}
+		b.Err = strings.Join(reasons, ", ")
+		for _, tp := range cal.Busy {
+			start, err := time.Parse(time.RFC3339, tp.Start)
+			if err != nil {
+				return nil, err
+			}
+			end, err := time.Parse(time.RFC3339, tp.End)
+			if err != nil {
+				return nil, err
+			}
+			b.Busy = append(b.Busy, period{Start: start, End: end})
+		}
+		result = append(result, b)
+	}
+	return result, nil
+}
+
+// writeFreeBusy lists each person's busy blocks under their address, or as
+// JSON with --output json.
+func writeFreeBusy(w io.Writer, result []busyTimes) error {
+	if *outputFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	for i, b := range result {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, CaptionStyle.Render(b.ID))
+		switch {
+		case b.Err != "":
+			fmt.Fprintf(w, "  unavailable (%s)\n", b.Err)
+		case len(b.Busy) == 0:
+			fmt.Fprintln(w, "  free")
+		}
+		for _, p := range b.Busy {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+	return nil
+}
+
+// freebusyWindow resolves --from and --to for the scheduling commands: from
+// now for a day unless given.
+func freebusyWindow(now time.Time) (time.Time, time.Time, error) {
+	from := now
+	var err error
+	if *fromFlag != "" {
+		if from, err = parseDate(*fromFlag, now); err != nil {
+			return from, from, fmt.Errorf("--from: %w", err)
+		}
+	}
+	to := from.AddDate(0, 0, 1)
+	if *toFlag != "" {
+		if to, err = parseDate(*toFlag, now); err != nil {
+			return from, to, fmt.Errorf("--to: %w", err)
+		}
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("--to must be after --from")
+	}
+	return from, to, nil
+}
+
+// freebusyAttendees is the freebusy command's --attendee flag.
+var freebusyAttendees stringList
+
+var freebusyCmd = &cobra.Command{
+	Use:   "freebusy",
+	Short: "Show when people are busy",
+	Long: "Print each --attendee's busy blocks between --from and --to, by default the next 24 hours. " +
+		"Without --attendee the selected calendars are shown.\n\n" +
+		"Only blocks are shown, not what they are; people whose calendar is not shared with you are reported as unavailable.",
+	Example: `  go-gcal-cli freebusy --attendee a@example.com --attendee b@example.com --from tomorrow --to +2d`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, to, err := freebusyWindow(time.Now())
+		if err != nil {
+			fatal("Invalid time window", &UsageError{err})
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		ids := []string(freebusyAttendees)
+		if len(ids) == 0 {
+			ids = calendarIDs()
+		}
+		result, err := queryFreeBusy(ctx, srv, ids, from, to)
+		if err != nil {
+			fatal("Unable to query free/busy", err)
+		}
+		if err := writeFreeBusy(os.Stdout, result); err != nil {
+			fatal("Unable to write free/busy", err)
+		}
+	},
+}
+
+func init() {
+	freebusyCmd.Flags().Var(&freebusyAttendees, "attendee", "email address or calendar ID to look up; repeatable")
+	rootCmd.AddCommand(freebusyCmd)
+}
@@ -20,15 +20,23 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
@@ -37,95 +45,435 @@ import (
 
 // Retrieve a token, saves the token, then returns the generated client.
 func getClient(config *oauth2.Config) *http.Client {
-	// The file token.json stores the user's access and refresh tokens, and is
-	// created automatically when the authorization flow completes for the first
+	// The token store holds the user's access and refresh tokens, and is
+	// filled automatically when the authorization flow completes for the first
 	// time.
-	tokFile := "token.json"
-	tok, err := tokenFromFile(tokFile)
+	store, err := openTokenStore()
 	if err != nil {
-		tok = getTokenFromWeb(config)
-		saveToken(tokFile, tok)
+		fatal("Unable to open token store", err)
 	}
-	return config.Client(context.Background(), tok)
+	tok, err := store.Load()
+	if errors.Is(err, errNoToken) {
+		tok, err = importLegacyToken(store)
+	} else if err != nil {
+		fmt.Printf("Unable to load saved token (%v), re-authorizing.\n", err)
+	}
+	if err == nil && !coversScopes(tok.Scopes, config.Scopes) {
+		fmt.Printf("Saved token was granted %v but %v is required, re-authorizing.\n", tok.Scopes, config.Scopes)
+		err = errScopeMismatch
+	}
+	if err != nil {
+		tok = authorize(config)
+		saveToken(store, tok)
+	}
+	return config.Client(context.Background(), &tok.Token)
+}
+
+// authorize runs the OAuth flow and records which scopes were granted,
+// warning when the user declined some of them.
+func authorize(config *oauth2.Config) *storedToken {
+	tok := getTokenFromWeb(config)
+	scopes := grantedScopes(tok, config.Scopes)
+	if !coversScopes(scopes, config.Scopes) {
+		fmt.Printf("Only %v was granted of %v; commands needing the rest will fail until you authorize again.\n", scopes, config.Scopes)
+	}
+	return &storedToken{Token: *tok, Scopes: scopes}
 }
 
 // Request a token from the web, then returns the retrieved token.
 func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	switch *authFlow {
+	case "manual":
+		return getTokenFromPaste(config)
+	case "device":
+		tok, err := tokenFromDevice(ctx, config)
+		if err != nil {
+			fatal("Unable to retrieve token from device flow", err)
+		}
+		return tok
+	}
+	tok, err := tokenFromCallback(ctx, config)
+	if err == nil {
+		return tok
+	}
+	if !errors.Is(err, errNoLoopback) {
+		fatal("Unable to retrieve token from web", err)
+	}
+	fmt.Printf("%v, falling back to manual code entry.\n", err)
+	return getTokenFromPaste(config)
+}
+
+// getTokenFromPaste asks the user to copy the authorization code by hand.
+func getTokenFromPaste(config *oauth2.Config) *oauth2.Token {
 	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
 	fmt.Printf("Go to the following link in your browser then type the "+
 		"authorization code: \n%v\n", authURL)
 
 	var authCode string
 	if _, err := fmt.Scan(&authCode); err != nil {
-		log.Fatalf("Unable to read authorization code: %v", err)
+		fatal("Unable to read authorization code", err)
 	}
 
 	tok, err := config.Exchange(context.TODO(), authCode)
 	if err != nil {
-		log.Fatalf("Unable to retrieve token from web: %v", err)
+		fatal("Unable to retrieve token from web", err)
 	}
 	return tok
 }
 
-// Retrieves a token from a local file.
-func tokenFromFile(file string) (*oauth2.Token, error) {
+// storedToken is the on-disk token format. Scopes records what the token was
+// granted so that a change of --scope triggers a fresh authorization.
+type storedToken struct {
+	oauth2.Token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+var errScopeMismatch = errors.New("token scopes do not match")
+
+// Retrieves a token from a local plaintext file, as written by earlier
+// versions.
+func tokenFromFile(file string) (*storedToken, error) {
 	f, err := os.Open(file)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	tok := &oauth2.Token{}
+	tok := &storedToken{}
 	err = json.NewDecoder(f).Decode(tok)
+	if len(tok.Scopes) == 0 {
+		tok.Scopes = legacyScopes
+	}
 	return tok, err
 }
 
-// Saves a token to a file path.
-func saveToken(path string, token *oauth2.Token) {
-	fmt.Printf("Saving credential file to: %s\n", path)
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		log.Fatalf("Unable to cache oauth token: %v", err)
+// Saves a token to the token store.
+func saveToken(store tokenStore, token *storedToken) {
+	fmt.Printf("Saving credential to the %s\n", store)
+	if err := store.Save(token); err != nil {
+		fatal("Unable to cache oauth token", err)
 	}
-	defer f.Close()
-	json.NewEncoder(f).Encode(token)
 }
 
-type model struct {
-	events []*calendar.Event
+const (
+	// ClientSecretPath is the path to the client secret file.
+	startedMeeting = "+"
+	nextMeeting    = ">"
+)
+
+// eventTimes parses the concrete start and end of a timed event, in the
+// local time zone (--tz). It reports false for all-day events and anything
+// else without a usable DateTime.
+func eventTimes(item *calendar.Event) (time.Time, time.Time, bool) {
+	if item.Start == nil || item.End == nil || item.Start.DateTime == "" || item.End.DateTime == "" {
+		return time.Time{}, time.Time{}, false
+	}
+	startTime, ok := parseDateTime(item.Start)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	endTime, ok := parseDateTime(item.End)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	return startTime, endTime, true
+}
+
+// isRecurringMaster reports whether item is the parent of a recurring series
+// rather than a single instance. Its start is only the first occurrence, so
+// it must not be classified as started or next.
+func isRecurringMaster(item *calendar.Event) bool {
+	return len(item.Recurrence) > 0
 }
 
-func (m model) Init() tea.Cmd {
-	return nil
+// isOngoing reports whether now falls within [start, end).
+func isOngoing(now, start, end time.Time) bool {
+	return !now.Before(start) && now.Before(end)
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC:
-			return m, tea.Quit
+// pinOngoingEvents moves in-progress events to the front, keeping the
+// relative order of both the pinned and the remaining events.
+func pinOngoingEvents(items []*calendar.Event, now time.Time) []*calendar.Event {
+	var ongoing, rest []*calendar.Event
+	for _, item := range items {
+		startTime, endTime, ok := eventTimes(item)
+		if ok && !isRecurringMaster(item) && isOngoing(now, startTime, endTime) {
+			ongoing = append(ongoing, item)
+		} else {
+			rest = append(rest, item)
 		}
 	}
-	return m, nil
+	return append(ongoing, rest...)
 }
 
-const (
-	// ClientSecretPath is the path to the client secret file.
-	startedMeeting = "+"
-	nextMeeting    = ">"
-)
+// selfOrganized keeps only the events the authenticated user organizes.
+func selfOrganized(items []*calendar.Event) []*calendar.Event {
+	var kept []*calendar.Event
+	for _, item := range items {
+		if item.Organizer != nil && item.Organizer.Self {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
 
-func prepareTableRows(events calendar.Events) [][]string {
+// filterEvents applies the filters every command that shows events honors:
+// --self-only, --color-filter, --all-day, --show-declined and hiding events
+// outside working hours with --out-of-hours.
+func filterEvents(items []*calendar.Event) []*calendar.Event {
+	if *selfOnly {
+		items = selfOrganized(items)
+	}
+	items = hideDeclined(filterAllDay(filterColor(items)))
+	if *outOfHours != "hide" {
+		return items
+	}
+	// --working-hours was validated with the other global flags.
+	hours, _ := configuredHours()
+	var kept []*calendar.Event
+	for _, item := range items {
+		if !hours.outside(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
 
-	var rows [][]string
+// truncate shortens s to n terminal columns, ending in an ellipsis, unless
+// --no-truncate is set. Wide characters such as CJK count twice.
+func truncate(s string, n int) string {
+	if *noTruncate {
+		return s
+	}
+	return runewidth.Truncate(s, n, "...")
+}
+
+// minSummaryWidth is as narrow as fitSummaries squeezes the summary column.
+const minSummaryWidth = 12
+
+// terminalWidth returns the width of the terminal stdout is, or $COLUMNS,
+// or 0 when neither is known.
+func terminalWidth() int {
+	if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+		return w
+	}
+	w, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return w
+}
+
+// fitSummaries shortens the summary column, summaryCol, so that the table
+// fits the terminal, leaving the other columns whole.
+func fitSummaries(rows [][]string, headers []string, summaryCol int) {
+	width := terminalWidth()
+	if width == 0 || len(rows) == 0 || summaryCol < 0 {
+		return
+	}
+	cols := len(rows[0])
+	// Every column is followed by a border, as is the left edge.
+	used := cols + 1
+	for col := 0; col < cols; col++ {
+		if col == summaryCol {
+			continue
+		}
+		w := 0
+		if col < len(headers) {
+			w = runewidth.StringWidth(headers[col])
+		}
+		for _, row := range rows {
+			w = max(w, runewidth.StringWidth(row[col]))
+		}
+		used += w
+	}
+	budget := max(minSummaryWidth, width-used)
+	for _, row := range rows {
+		row[summaryCol] = truncate(row[summaryCol], budget)
+	}
+}
+
+// formatTimeRange renders an event's times as a single "09:00–09:30" cell,
+// or "Mon 23:00 → Tue 02:00" for one that runs past midnight.
+func formatTimeRange(item *calendar.Event) string {
+	startTime, endTime, ok := eventTimes(item)
+	if !ok {
+		return "all day"
+	}
+	startCell, endCell := timeCells(startTime, endTime)
+	sep := "–"
+	if spansDays(startTime, endTime) {
+		sep = " → "
+	}
+	return startCell + sep + endCell + dualTime(item, startTime)
+}
+
+// spansDays reports whether a timed event ends on a later local day than it
+// starts. Ending at midnight still counts as the day it started.
+func spansDays(start, end time.Time) bool {
+	return end.After(start) && !startOfDay(start).Equal(startOfDay(end.Add(-time.Nanosecond)))
+}
+
+// timeCells formats the start and end columns, with the weekday on both for
+// events that run past midnight.
+func timeCells(start, end time.Time) (string, string) {
+	startCell, endCell := start.Format(startLayout()), end.Format("15:04")
+	if spansDays(start, end) {
+		if startLayout() == "15:04" {
+			startCell = start.Format("Mon 15:04")
+		}
+		endCell = end.Format("Mon 15:04")
+	}
+	return startCell, endCell
+}
+
+// startLayout is the time layout of the start column. Views that can span
+// several days prefix it with the date.
+func startLayout() string {
+	if *nextCount > 0 {
+		return "Mon Jan 2 15:04"
+	}
+	return "15:04"
+}
+
+// maxRows caps the number of table rows: --next, else --limit unless
+// --no-limit is set.
+func maxRows() int {
+	if *nextCount > 0 {
+		return *nextCount
+	}
+	if *noLimit {
+		return math.MaxInt
+	}
+	return *limitFlag
+}
+
+// eventRow builds the table cells for one event, prefixing the summary with
+// the started or next marker that renderTable keys its row styles on.
+func eventRow(item *calendar.Event, now time.Time) []string {
+	summary := rsvpSummary(item)
+	startTime, endTime, ok := eventTimes(item)
+	if !ok || isRecurringMaster(item) {
+		// Leave all-day events and series masters unmarked; see
+		// isRecurringMaster.
+	} else if isOngoing(now, startTime, endTime) {
+		summary = startedMeeting + summary
+	} else if startTime.After(now) && startTime.Sub(now) < 10*time.Minute {
+		summary = nextMeeting + summary
+	}
+	summary = truncate(summary, 57)
+
+	startCell, endCell := "all day", ""
+	if ok {
+		startCell, endCell = timeCells(startTime, endTime)
+		startCell += dualTime(item, startTime)
+		endCell += dualTime(item, endTime)
+	}
+	columns := tableColumns()
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "summary":
+			row[i] = summary
+		case "start":
+			row[i] = startCell
+		case "end":
+			row[i] = endCell
+		case "time":
+			row[i] = formatTimeRange(item)
+		case "calendar":
+			row[i] = truncate(calendarName(eventCalendar(item)), 20)
+		case "link":
+			row[i] = eventLink(item, *linkSource)
+		case "attendees":
+			row[i] = truncate(attendeeList(item), 40)
+		case "color":
+			row[i] = truncate(colorCell(item), 20)
+		default:
+			row[i] = truncate(eventColumns[column](newEvent(item)), 40)
+		}
+	}
+	return row
+}
+
+// attendeeList names an event's guests for the table, by display name where
+// they have one.
+func attendeeList(item *calendar.Event) string {
+	names := make([]string, 0, len(item.Attendees))
+	for _, a := range item.Attendees {
+		if a.DisplayName != "" {
+			names = append(names, a.DisplayName)
+		} else {
+			names = append(names, a.Email)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// tableColumns returns the table's columns in order: --columns where set,
+// else summary, start, end and link with the calendar after the summary
+// when events come from several calendars. --merge-times shows start and
+// end as a single time column.
+func tableColumns() []string {
+	columns := []string{"summary", "start", "end", "link"}
+	// Compared with the default rather than checked with Changed, which
+	// columns in the config file do not set.
+	if *columnsFlag != globalFlags.Lookup("columns").DefValue {
+		// checkGlobalFlags has validated them.
+		columns, _ = parseColumns(*columnsFlag)
+	} else if multiCalendar() {
+		columns = slices.Insert(columns, 1, "calendar")
+	}
+	if !*mergeTimes {
+		return columns
+	}
+	var merged []string
+	for _, column := range columns {
+		switch column {
+		case "start":
+			merged = append(merged, "time")
+		case "end":
+			if !slices.Contains(columns, "start") {
+				merged = append(merged, "time")
+			}
+		default:
+			merged = append(merged, column)
+		}
+	}
+	return merged
+}
+
+// longEvents counts the timed events longer than 24 hours that have not
+// ended by now.
+func longEvents(items []*calendar.Event, now time.Time) int {
+	n := 0
+	for _, item := range items {
+		if startTime, endTime, ok := eventTimes(item); ok && endTime.Sub(startTime) > 24*time.Hour && endTime.After(now) {
+			n++
+		}
+	}
+	return n
+}
+
+// prepareTableRows builds the rows of the meetings still to come, up to
+// maxRows, below the current all-day events, which do not count towards it.
+// It also returns the event of each row and how many meetings did not fit.
+func prepareTableRows(events calendar.Events) ([][]string, []*calendar.Event, int) {
+
+	var allDay, rows [][]string
+	var allDayItems, rowItems []*calendar.Event
+	more := 0
 	var timeNow = time.Now()
 	for _, item := range events.Items {
-		date := item.Start.DateTime
-		if date == "" { // remove all day events
+		if isAllDay(item) {
+			if eventEnd(item).After(timeNow) {
+				allDay = append(allDay, eventRow(item, timeNow))
+				allDayItems = append(allDayItems, item)
+			}
+			continue
+		}
+		startTime, endTime, ok := eventTimes(item)
+		if !ok {
 			continue
 		}
-		startTime, _ := time.Parse(time.RFC3339, item.Start.DateTime)
-		endTime, _ := time.Parse(time.RFC3339, item.End.DateTime)
 
 		if startTime == endTime {
 			continue
@@ -135,170 +483,375 @@ func prepareTableRows(events calendar.Events) [][]string {
 			continue
 		}
 
-		if endTime.Sub(startTime) > 24*time.Hour {
+		if endTime.Sub(startTime) > 24*time.Hour && !*includeLong {
 			continue
 		}
 
-		if timeNow.After(startTime) && timeNow.Before(endTime) {
-			item.Summary = startedMeeting + item.Summary
-		} else if startTime.Sub(timeNow) < 10*time.Minute {
-			item.Summary = nextMeeting + item.Summary
+		if len(rows) < maxRows() {
+			rows = append(rows, eventRow(item, timeNow))
+			rowItems = append(rowItems, item)
+		} else {
+			more++
 		}
+	}
+	return append(allDay, rows...), append(allDayItems, rowItems...), more
 
-		if len(item.Summary) > 57 {
-			item.Summary = item.Summary[:57] + "..."
+}
+
+// globalFlags are shared by every command and registered as persistent flags
+// on the root command.
+var globalFlags = pflag.NewFlagSet("go-gcal-cli", pflag.ExitOnError)
+
+var (
+	pinOngoing     = globalFlags.Bool("pin-ongoing", false, "keep in-progress meetings at the top of the table")
+	outputFormat   = globalFlags.String("output", "table", "output format: table, json, csv, tsv, png or prometheus")
+	outPath        = globalFlags.String("out", "", "file to write binary output formats (png) to")
+	selfOnly       = globalFlags.Bool("self-only", false, "only show events organized by you")
+	fromFlag       = globalFlags.String("from", "", "start of the time window (e.g. today, -1d, 2 weeks ago, 2024-06-03)")
+	toFlag         = globalFlags.String("to", "", "end of the time window (e.g. tomorrow, +3d, friday 6pm)")
+	noSingle       = globalFlags.Bool("no-single-events", false, "list recurring series masters instead of expanding instances")
+	conflictsOnly  = globalFlags.Bool("conflicts-only", false, "only show overlapping (double-booked) events")
+	nextCount      = globalFlags.Int("next", 0, "show the next N meetings from now, however many days that spans")
+	mergeTimes     = globalFlags.Bool("merge-times", false, "show start and end in a single Time column")
+	noTruncate     = globalFlags.Bool("no-truncate", false, "never shorten summaries, even if rows overflow")
+	linkSource     = globalFlags.String("link-source", "auto", "where the Link column comes from: auto, conference, hangout, location or description")
+	noHeader       = globalFlags.Bool("no-header", false, "omit the table header row")
+	caption        = globalFlags.String("caption", "", "title line rendered above the table")
+	timeout        = globalFlags.Duration("timeout", 0, "give up on API calls after this long (e.g. 30s); 0 means no limit")
+	tokenStoreFlag = globalFlags.String("token-store", "auto", "where to keep the OAuth token: auto, keyring or file (encrypted with $"+passphraseEnv+")")
+	authFlow       = globalFlags.String("auth-flow", "browser", "how to authorize: browser (localhost callback), device (for headless machines) or manual (paste the code)")
+	migrate        = globalFlags.Bool("migrate", false, "move token and credentials from the current directory to the config and cache directories")
+	configDirFlag  = globalFlags.String("config-dir", "", "directory for credentials, tokens and cache, overriding the XDG locations")
+	profileFlag    = globalFlags.String("profile", "", "account profile to use (default $"+profileEnv+" or \"default\")")
+	serviceAccount = globalFlags.String("service-account", "", "service account key file to authorize with instead of the OAuth flow")
+	impersonate    = globalFlags.String("impersonate", "", "user to act as via domain-wide delegation (with --service-account)")
+	useADC         = globalFlags.Bool("adc", false, "authorize with Application Default Credentials ($GOOGLE_APPLICATION_CREDENTIALS or gcloud)")
+	writeAccess    = globalFlags.Bool("write", false, "request read-write access to events, as commands that change the calendar need")
+	tzFlag         = globalFlags.String("tz", "", "IANA time zone to show times in (default the system time zone)")
+	columnsFlag    = globalFlags.String("columns", "summary,start,end,link", "fields for the table, csv and tsv output: id, calendar, summary, start, end, all_day, location, link, organizer, attendees, color")
+	formatFlag     = globalFlags.String("format", "", "Go template printed per event instead of --output, e.g. '{{.Summary}} {{.Start.Format \"15:04\"}}'")
+	themeFlag      = globalFlags.String("theme", "dark", "color theme: dark, light, solarized, no-color or one defined under themes: in the config file")
+	noColor        = globalFlags.Bool("no-color", false, "print plain text without ANSI styling; also set by $NO_COLOR or when stdout is not a terminal")
+	allCalendars   = globalFlags.Bool("all-calendars", false, "list events from every calendar in your calendar list, not just --calendar")
+	noCache        = globalFlags.Bool("no-cache", false, "fetch events from the API instead of the local event cache")
+	offline        = globalFlags.Bool("offline", false, "show cached events only, without contacting the API")
+	parallel       = globalFlags.Int("parallel", 4, "most calendars to fetch from at once")
+	maxAttempts    = globalFlags.Int("max-attempts", 5, "tries per API request on rate limits and, for reads and deletes, server errors, with exponential backoff; 1 disables retries")
+	allDayFlag     = globalFlags.String("all-day", "show", "all-day events: show (pinned above the meetings), hide or only")
+	dualTimeFlag   = globalFlags.Bool("dual-time", false, "also show times in the zone an event was scheduled in, where that differs from --tz")
+	includeLong    = globalFlags.Bool("include-long-events", false, "also list timed events longer than 24 hours in the table")
+	showDeclined   = globalFlags.Bool("show-declined", false, "also list invitations you declined")
+	limitFlag      = globalFlags.Int("limit", 6, "most meetings the table shows")
+	noLimit        = globalFlags.Bool("no-limit", false, "show every meeting in the window, however many")
+	workHours      = globalFlags.String("working-hours", "09:00-17:00", "time of day you work; slot and availability only offer times within it")
+	workDays       = globalFlags.String("working-days", "mon-fri", "days you work, e.g. mon-fri or sun-thu")
+	outOfHours     = globalFlags.String("out-of-hours", "show", "events outside working hours: show them, dim them in agenda or hide them")
+	colorFilter    = globalFlags.String("color-filter", "", "in list, agenda, search and the TUI, only show events of this color, e.g. banana; none for those in their calendar's color")
+	quotaUser      = globalFlags.String("quota-user", "", "quotaUser sent with API calls to separate rate-limit buckets per user")
+)
+
+var (
+	HeaderStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Background(lipgloss.Color("0"))
+	NormalStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("7")).Background(lipgloss.Color("0"))
+	StartedRowStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Background(lipgloss.Color("#1F4E79"))
+	NextRowStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#00FF00"))
+	CaptionStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("99"))
+	ConflictRowStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700")).Background(lipgloss.Color("0"))
+	DimRowStyle      = NormalStyle.Faint(true)
+)
+
+// timeWindow resolves the --from/--to flags, defaulting to one day either
+// side of now.
+func timeWindow(fromValue, toValue string, now time.Time) (time.Time, time.Time, error) {
+	from, to := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+	var err error
+	if fromValue != "" {
+		if from, err = parseDate(fromValue, now); err != nil {
+			return from, to, fmt.Errorf("--from: %w", err)
+		}
+	}
+	if toValue != "" {
+		if to, err = parseDate(toValue, now); err != nil {
+			return from, to, fmt.Errorf("--to: %w", err)
 		}
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("--to (%s) must be after --from (%s)", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	return from, to, nil
+}
 
-		rows = append(rows, []string{item.Summary, startTime.Format("15:04"), endTime.Format("15:04"), item.HangoutLink})
-		if len(rows) > 5 {
-			return rows
+// columnHeaders title the table columns that are not clocks.
+var columnHeaders = map[string]string{
+	"id":        "ID",
+	"calendar":  "Calendar",
+	"summary":   "Summary",
+	"end":       "End",
+	"all_day":   "All day",
+	"location":  "Location",
+	"link":      "Link",
+	"organizer": "Organizer",
+	"attendees": "Attendees",
+	"color":     "Color",
+}
+
+// tableHeaders returns the header row matching tableColumns. The start or
+// time column header doubles as a clock.
+func tableHeaders(now time.Time) []string {
+	var headers []string
+	for _, column := range tableColumns() {
+		switch column {
+		case "start":
+			headers = append(headers, now.Format("15:04"))
+		case "time":
+			headers = append(headers, "Time ("+now.Format("15:04")+")")
+		default:
+			headers = append(headers, columnHeaders[column])
 		}
 	}
-	return rows
+	return headers
+}
 
+// calendarColor returns the color Google Calendar shows calendar id in, or
+// "" when unknown.
+func calendarColor(id string) lipgloss.Color {
+	if entry := calendarEntry(id); entry != nil {
+		return lipgloss.Color(entry.BackgroundColor)
+	}
+	return ""
 }
 
-func (m model) View() string {
-	var output string
+// renderTable lays out the prepared rows as a bordered lipgloss table, with
+// the rows set in dimmed drawn faint. items holds the event of each row, for
+// coloring its calendar.
+func renderTable(rows [][]string, items []*calendar.Event, dimmed map[int]bool) string {
+	var headers []string
+	if !*noHeader {
+		headers = tableHeaders(time.Now())
+	}
+	columns := tableColumns()
+	summaryCol, calendarCol := slices.Index(columns, "summary"), slices.Index(columns, "calendar")
+	colorCol := slices.Index(columns, "color")
+	fitSummaries(rows, headers, summaryCol)
+	tbl := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(colors.Accent)).
+		StyleFunc(func(row, col int) lipgloss.Style {
+
+			if row == table.HeaderRow {
+				return HeaderStyle
+			}
 
-	header := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("0")).Render
-	oldStyle := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(lipgloss.Color("9")).Background(lipgloss.Color("0")).Render
-	newStyle := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(lipgloss.Color("10")).Background(lipgloss.Color("0")).Render
-	currentStyle := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(lipgloss.Color("2")).Background(lipgloss.Color("0")).Render
+			if row > -1 && summaryCol >= 0 {
+				summary := rows[row][summaryCol]
+				if len(summary) > 0 && summary[0] == nextMeeting[0] {
+					return NextRowStyle
+				}
 
-	output += header(fmt.Sprintf("%-50s %-5s-%-5s %-20s\n", "Summary", "Start", "End", "Hangout Link"))
+				if len(summary) > 0 && summary[0] == startedMeeting[0] {
+					return StartedRowStyle
+				}
 
-	for i, event := range m.events {
-		startTime, _ := time.Parse(time.RFC3339, event.Start.DateTime)
-		endTime, _ := time.Parse(time.RFC3339, event.End.DateTime)
-		now := time.Now()
+				if strings.HasPrefix(summary, conflictMarker) {
+					return ConflictRowStyle
+				}
+			}
 
-		if event.Start.DateTime == "" {
-			continue
-		}
+			if dimmed[row] {
+				return DimRowStyle
+			}
 
-		style := oldStyle
-		if startTime.Before(now) {
-			style = oldStyle
-		} else if endTime.Before(now) {
-			style = newStyle
-		} else {
-			style = currentStyle
-		}
+			if row > -1 && col == calendarCol {
+				if c := calendarColor(eventCalendar(items[row])); c != "" {
+					return NormalStyle.Foreground(c)
+				}
+			}
 
-		if len(event.Summary) > 47 {
-			event.Summary = event.Summary[:47] + "..."
-		}
-		output += style(fmt.Sprintf("%-50s %-5s-%-5s %-20s\n", event.Summary, startTime.Format("15:04"), endTime.Format("15:04"), event.HangoutLink))
+			if row > -1 && col == colorCol {
+				if c := eventColor(items[row]); c != "" {
+					return NormalStyle.Foreground(c)
+				}
+			}
 
-		//		output += style.Render(fmt.Sprintf("%-30s %-20s %-20s %-50s\n", event.Summary, startTime.Format("15:04"), endTime.Format("15:04"), event.HangoutLink))
-		if i == 10 {
-			break
-		}
+			return NormalStyle
+		}).
+		Rows(rows...)
+	if !*noHeader {
+		tbl = tbl.Headers(headers...)
 	}
 
-	return output
-}
-
-func runBubbleTea(events []*calendar.Event) {
-	p := tea.NewProgram(model{events: events})
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v\n", err)
+	if *caption != "" {
+		return CaptionStyle.Render(*caption) + "\n" + tbl.Render()
 	}
+	return tbl.Render()
 }
 
-var (
-	HeaderStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Background(lipgloss.Color("0"))
-	NormalStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("7")).Background(lipgloss.Color("0"))
-	StartedRowStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#62D9F5")).Background(lipgloss.Color("#0000FF"))
-	NextRowStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#00FF00"))
-)
+// scopeFlags and calendarFlags collect the repeatable --scope and
+// --calendar flags.
+var scopeFlags, calendarFlags stringList
 
-func main() {
-	ctx := context.Background()
-	b, err := os.ReadFile("go-gcal-cli-credentials.json")
+// oauthConfig loads the OAuth client credentials for the requested scopes.
+func oauthConfig() *oauth2.Config {
+	b, err := os.ReadFile(configPath(credentialsFile))
 	if err != nil {
-		log.Fatalf("Unable to read client secret file: %v", err)
+		log.Fatalf("Unable to read client secret file: %v; run \"go-gcal-cli init\" to set one up", err)
 	}
 
-	// If modifying these scopes, delete your previously saved token.json.
-	config, err := google.ConfigFromJSON(b, calendar.CalendarReadonlyScope)
+	// Changing scopes triggers re-authorization; see getClient.
+	config, err := google.ConfigFromJSON(b, requestedScopes()...)
 	if err != nil {
-		log.Fatalf("Unable to parse client secret file to config: %v", err)
+		fatal("Unable to parse client secret file to config", err)
 	}
-	client := getClient(config)
+	return config
+}
 
-	srv, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+// requestedScopes resolves --scope, exiting on invalid values.
+func requestedScopes() []string {
+	scopes, err := resolveScopes(scopeFlags, *writeAccess)
 	if err != nil {
-		log.Fatalf("Unable to retrieve Calendar client: %v", err)
+		fatal("Invalid --scope", &UsageError{err})
 	}
+	return scopes
+}
 
-	t := time.Now().AddDate(0, 0, -1).Format(time.RFC3339)
+// httpClient returns an authorized client: a service account when
+// --service-account is set, Application Default Credentials with --adc or
+// when no OAuth client file exists, otherwise the user's saved token.
+func httpClient() *http.Client {
+	if *serviceAccount != "" {
+		return serviceAccountClient(*serviceAccount, *impersonate)
+	}
+	if *useADC {
+		return adcClient()
+	}
+	if !fileExists(configPath(credentialsFile)) && adcAvailable() {
+		fmt.Fprintln(os.Stderr, "No OAuth client credentials found, using Application Default Credentials.")
+		return adcClient()
+	}
+	return getClient(oauthConfig())
+}
 
-	tMax := time.Now().AddDate(0, 0, 1).Format(time.RFC3339)
-	//events, err := srv.Events.List("primary").ShowDeleted(false).SingleEvents(true).TimeMin(t).TimeMax(tMax).OrderBy("startTime").Do()
-	events, err := srv.Events.List("primary").ShowDeleted(false).SingleEvents(true).TimeMin(t).TimeMax(tMax).OrderBy("startTime").Do()
+// newService authorizes, interactively if needed, and returns a Calendar
+// client. Calendars selected by name are resolved to their IDs on the way.
+func newService() *calendar.Service {
+	client := retryClient(httpClient())
 
+	srv, err := calendar.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Unable to retrieve next ten of the user's events: %v", err)
+		fatal("Unable to retrieve Calendar client", err)
 	}
 
-	//style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")).Background(lipgloss.Color("0")).Render
+	ctx, cancel := requestContext()
+	defer cancel()
+	if err := resolveCalendarNames(ctx, srv); err != nil {
+		fatal("Invalid --calendar", err)
+	}
+	return srv
+}
 
-	if len(events.Items) == 0 {
-		fmt.Println("No upcoming events found.")
-	} else {
-		for _, item := range events.Items {
-			date := item.Start.DateTime
-			if date == "" {
-				continue
-				//date = item.Start.Date
-			}
-			//fmt.Printf("%v (%v) %v\n", item.Summary, date, item.HangoutLink)
-			startTime, _ := time.Parse(time.RFC3339, item.Start.DateTime)
-			endTime, _ := time.Parse(time.RFC3339, item.End.DateTime)
+// runList is the default command: a short table of what is on now and next.
+func runList(cmd *cobra.Command, args []string) {
+	srv := newService()
 
-			if startTime == endTime {
-				continue
-			}
+	// Start the clock only now so that --timeout does not cover the
+	// interactive authorization.
+	ctx, cancel := requestContext()
+	defer cancel()
 
-			if len(item.Summary) > 47 {
-				item.Summary = item.Summary[:47] + "..."
+	var err error
+	var events *calendar.Events
+	if *nextCount > 0 {
+		events, err = fetchUpcoming(ctx, srv, time.Now(), *nextCount)
+	} else {
+		from, to, werr := timeWindow(*fromFlag, *toFlag, time.Now())
+		if werr != nil {
+			usageFatalf("Invalid time window: %v", werr)
+		}
+		events, err = fetchEvents(ctx, srv, from, to)
+		if err == nil {
+			// Keep this fetch around so that diff can report changes later.
+			snap := &snapshot{Fetched: time.Now(), From: from, To: to, Events: events.Items}
+			if serr := saveSnapshot(snap); serr != nil {
+				log.Printf("Unable to save event snapshot: %v", serr)
 			}
-
-			//		fmt.Printf("%-50s %-5s-%-5s %-20s\n", item.Summary, startTime.Format("15:04"), endTime.Format("15:04"), item.HangoutLink)
-			//fmt.Printf(style(fmt.Sprintf("%v\t%v\t%v\t%v\n", item.Summary, startTime.Format("15:04"), endTime.Format("15:04"), item.HangoutLink)))
 		}
 	}
 
-	rows := prepareTableRows(*events)
+	if err != nil {
+		fatal("Unable to retrieve next ten of the user's events", err)
+	}
+	warnStale(os.Stderr)
 
-	tbl := table.New().
-		Border(lipgloss.NormalBorder()).
-		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
-		StyleFunc(func(row, col int) lipgloss.Style {
+	events.Items = filterEvents(events.Items)
 
-			if row == -1 {
-				return HeaderStyle
+	switch {
+	case *outputFormat == "prometheus":
+		if err := writeMetrics(os.Stdout, events.Items, time.Now()); err != nil {
+			fatal("Unable to write metrics", err)
+		}
+		return
+	case *formatFlag != "", *outputFormat == "json", *outputFormat == "csv", *outputFormat == "tsv":
+		if *conflictsOnly {
+			conflicts := findConflicts(events.Items)
+			var kept []*calendar.Event
+			for _, item := range events.Items {
+				if conflicts[item] {
+					kept = append(kept, item)
+				}
 			}
+			events.Items = kept
+		}
+		if _, err := writeEvents(os.Stdout, events.Items); err != nil {
+			fatal("Unable to write events", err)
+		}
+		return
+	}
 
-			if row > -1 {
-				if len(rows[row][0]) > 0 && rows[row][0][0] == nextMeeting[0] {
-					return NextRowStyle
-				}
+	if len(events.Items) == 0 {
+		fmt.Println("No upcoming events found.")
+	}
 
-				if len(rows[row][0]) > 0 && rows[row][0][0] == startedMeeting[0] {
-					return StartedRowStyle
-				}
+	loadColorColumn(ctx, srv)
 
-			}
+	var conflicts int
+	events.Items, conflicts = markConflicts(events.Items, *conflictsOnly, time.Now())
 
-			return NormalStyle
-		}).
-		Headers("Summary", time.Now().Format("15:04"), "End", "Link").
-		Rows(rows...)
+	if *pinOngoing {
+		events.Items = pinOngoingEvents(events.Items, time.Now())
+	}
+
+	rows, rowItems, more := prepareTableRows(*events)
+
+	switch *outputFormat {
+	case "png":
+		if *outPath == "" {
+			usageFatalf("--output png requires an explicit --out path")
+		}
+		view := renderImage(func() string { return renderTable(rows, rowItems, nil) })
+		if err := renderPNG(view, *outPath); err != nil {
+			fatal("Unable to write image", err)
+		}
+	default:
+		fmt.Println(renderTable(rows, rowItems, nil))
+		if conflicts > 0 {
+			fmt.Printf("%s %d upcoming events overlap others\n", conflictMarker, conflicts)
+		}
+		if more > 0 {
+			fmt.Printf("%d more events not shown; raise --limit or add --no-limit\n", more)
+		}
+		if n := longEvents(events.Items, time.Now()); n > 0 && !*includeLong {
+			fmt.Printf("%d events longer than 24 hours not shown; add --include-long-events\n", n)
+		}
+	}
 
-	fmt.Println(tbl.Render())
-	//runBubbleTea(events.Items)
+}
 
+func main() {
+	// Errors are for people, not log files; the daemon has its own logger.
+	log.SetFlags(0)
+	// Commands report their own failures, so what reaches here are unknown
+	// commands and flags or wrong arguments.
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(exitUsage)
+	}
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// timed returns a timed event running from now+start to now+end.
+func timed(summary string, now time.Time, start, end time.Duration) *calendar.Event {
+	return &calendar.Event{
+		Summary: summary,
+		Start:   &calendar.EventDateTime{DateTime: now.Add(start).Format(time.RFC3339)},
+		End:     &calendar.EventDateTime{DateTime: now.Add(end).Format(time.RFC3339)},
+	}
+}
+
+func TestPrepareTableRowsMarkers(t *testing.T) {
+	now := time.Now()
+	today := startOfDay(now)
+	master := &calendar.Event{
+		Summary:    "Series",
+		Recurrence: []string{"RRULE:FREQ=WEEKLY"},
+		Start:      &calendar.EventDateTime{},
+	}
+	runningMaster := timed("Running series", now, -10*time.Minute, 20*time.Minute)
+	runningMaster.Recurrence = []string{"RRULE:FREQ=DAILY"}
+	allDay := &calendar.Event{
+		Summary: "Holiday",
+		Start:   &calendar.EventDateTime{Date: today.Format("2006-01-02")},
+		End:     &calendar.EventDateTime{Date: today.AddDate(0, 0, 1).Format("2006-01-02")},
+	}
+	items := []*calendar.Event{
+		master,
+		allDay,
+		timed("Started", now, -10*time.Minute, 20*time.Minute),
+		runningMaster,
+		timed("Next", now, 5*time.Minute, 35*time.Minute),
+		timed("Later", now, 2*time.Hour, 3*time.Hour),
+		timed("Over", now, -2*time.Hour, -time.Hour),
+	}
+
+	rows, rowItems, more := prepareTableRows(calendar.Events{Items: items})
+	if more != 0 {
+		t.Errorf("more = %d, want 0", more)
+	}
+	want := []string{"Holiday", startedMeeting + "Started", "Running series", nextMeeting + "Next", "Later"}
+	if len(rows) != len(want) || len(rowItems) != len(want) {
+		t.Fatalf("got %d rows %v, want %d", len(rows), rows, len(want))
+	}
+	for i, summary := range want {
+		if rows[i][0] != summary {
+			t.Errorf("row %d summary = %q, want %q", i, rows[i][0], summary)
+		}
+	}
+}
+
+func TestEventRowMarkers(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.Local)
+	master := &calendar.Event{Summary: "Series", Recurrence: []string{"RRULE:FREQ=WEEKLY"}, Start: &calendar.EventDateTime{}}
+	runningMaster := timed("Running series", now, -time.Minute, time.Hour)
+	runningMaster.Recurrence = []string{"RRULE:FREQ=DAILY"}
+	allDay := &calendar.Event{
+		Summary: "Holiday",
+		Start:   &calendar.EventDateTime{Date: "2024-06-03"},
+		End:     &calendar.EventDateTime{Date: "2024-06-04"},
+	}
+	tests := []struct {
+		name   string
+		item   *calendar.Event
+		marker string
+		start  string
+	}{
+		{"recurring master without start", master, "", "all day"},
+		{"recurring master in progress", runningMaster, "", ""},
+		{"all-day event", allDay, "", "all day"},
+		{"started", timed("Started", now, -5*time.Minute, 25*time.Minute), startedMeeting, ""},
+		{"starts within 10 minutes", timed("Next", now, 9*time.Minute, time.Hour), nextMeeting, ""},
+		{"starts later", timed("Later", now, 10*time.Minute, time.Hour), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := eventRow(tt.item, now)
+			summary := row[0]
+			if got := strings.TrimSuffix(summary, tt.item.Summary); got != tt.marker {
+				t.Errorf("summary = %q, want marker %q", summary, tt.marker)
+			}
+			if tt.start != "" && row[1] != tt.start {
+				t.Errorf("start cell = %q, want %q", row[1], tt.start)
+			}
+		})
+	}
+}
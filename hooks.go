@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// hooksSetting is the config file section holding hooks, e.g.
+//
+//	hooks:
+//	  on_meeting_start: slack-status busy
+//	  on_meeting_end:
+//	    - slack-status clear
+//	    - hue-lights off
+//	  on_new_invite: notify-send "Invite: $GCAL_EVENT_SUMMARY"
+//
+// watch and daemon run them through the shell with the event in $GCAL_EVENT_*.
+const hooksSetting = "hooks"
+
+// The hooks watch and daemon run.
+const (
+	hookMeetingStart = "on_meeting_start"
+	hookMeetingEnd   = "on_meeting_end"
+	hookNewInvite    = "on_new_invite"
+)
+
+var hookNames = []string{hookMeetingStart, hookMeetingEnd, hookNewInvite}
+
+// parseHooks reads the hooks section of s. Each hook is one command or a
+// list of them.
+func parseHooks(s settings) (map[string][]string, error) {
+	hooks := map[string][]string{}
+	if s[hooksSetting] == nil {
+		return hooks, nil
+	}
+	b, err := yaml.Marshal(s[hooksSetting])
+	if err != nil {
+		return nil, err
+	}
+	var section map[string]any
+	if err := yaml.Unmarshal(b, &section); err != nil {
+		return nil, fmt.Errorf("%s must map hook names to commands: %w", hooksSetting, err)
+	}
+	for name, v := range section {
+		if !slices.Contains(hookNames, name) {
+			return nil, fmt.Errorf("unknown hook %q; use one of %s", name, strings.Join(hookNames, ", "))
+		}
+		switch v := v.(type) {
+		case string:
+			hooks[name] = []string{v}
+		case []any:
+			for _, c := range v {
+				hooks[name] = append(hooks[name], fmt.Sprint(c))
+			}
+		default:
+			return nil, fmt.Errorf("hook %s must be a command or a list of commands", name)
+		}
+	}
+	return hooks, nil
+}
+
+// hookEnv exposes item to a hook as environment variables.
+func hookEnv(hook string, item *calendar.Event) []string {
+	e := newEvent(item)
+	var attendees []string
+	for _, a := range e.Attendees {
+		attendees = append(attendees, a.Email)
+	}
+	vars := [][2]string{
+		{"HOOK", hook},
+		{"EVENT_ID", e.ID},
+		{"EVENT_CALENDAR", e.Calendar},
+		{"EVENT_SUMMARY", e.Summary},
+		{"EVENT_START", e.Start.Format(time.RFC3339)},
+		{"EVENT_END", e.End.Format(time.RFC3339)},
+		{"EVENT_LOCATION", e.Location},
+		{"EVENT_LINK", e.Link},
+		{"EVENT_URL", item.HtmlLink},
+		{"EVENT_ORGANIZER", e.Organizer},
+		{"EVENT_ATTENDEES", strings.Join(attendees, ",")},
+	}
+	env := os.Environ()
+	for _, v := range vars {
+		env = append(env, envPrefix+v[0]+"="+v[1])
+	}
+	return env
+}
+
+// shellCommand runs command through the platform's shell.
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// runHooks starts the commands of hook for item without waiting for them.
+// Failures are logged with the command's output.
+func (w *watcher) runHooks(hook string, item *calendar.Event) {
+	for _, command := range w.hooks[hook] {
+		cmd := shellCommand(command)
+		cmd.Env = hookEnv(hook, item)
+		var out bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &out, &out
+		w.log.Info("running hook", "hook", hook, "event", item.Id, "summary", item.Summary, "command", command)
+		if err := cmd.Start(); err != nil {
+			w.log.Warn("unable to run hook", "hook", hook, "command", command, "error", err)
+			continue
+		}
+		go func() {
+			if err := cmd.Wait(); err != nil {
+				w.log.Warn("hook failed", "hook", hook, "command", command, "error", err, "output", strings.TrimSpace(out.String()))
+			}
+		}()
+	}
+}
+
+// invitePending reports whether the user has yet to answer an invitation to
+// item.
+func invitePending(item *calendar.Event) bool {
+	for _, a := range item.Attendees {
+		if a.Self {
+			return a.ResponseStatus == "needsAction" && (item.Organizer == nil || !item.Organizer.Self)
+		}
+	}
+	return false
+}
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	icsDate     = "20060102"
+	icsDateTime = "20060102T150405Z"
+	// icsLocalTime is a DATE-TIME in the zone given by its TZID parameter.
+	icsLocalTime = "20060102T150405"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the events between --from and --to as an iCalendar file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := runExport(ctx, srv, os.Stdout); err != nil {
+			fatal("Unable to export events", err)
+		}
+	},
+}
+
+// runExport fetches recurring series as masters, so that their RRULE is
+// exported, with modified instances as separate RECURRENCE-ID events.
+func runExport(ctx context.Context, srv *calendar.Service, w io.Writer) error {
+	from, to, err := timeWindow(*fromFlag, *toFlag, time.Now())
+	if err != nil {
+		return err
+	}
+	*noSingle = true
+	events, err := fetchEvents(ctx, srv, from, to)
+	if err != nil {
+		return err
+	}
+	return writeICS(w, events.Items, time.Now())
+}
+
+// icsEscape escapes a TEXT value as RFC 5545 section 3.3.11 requires.
+var icsEscape = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsWriter writes content lines, folding them at 75 octets with CRLF line
+// endings. The first error sticks and is reported by err.
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *icsWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+	line := name + ":" + value
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		n := len(string(r))
+		if width+n > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	b.WriteString("\r\n")
+	_, iw.err = io.WriteString(iw.w, b.String())
+}
+
+// icsTime formats an event time as a DTSTART or DTEND property, returning
+// the property name with its parameters and the value. Times in a named zone
+// keep it, so that recurrences stay at the same wall-clock time across DST
+// changes.
+func icsTime(name string, t *calendar.EventDateTime) (string, string) {
+	if t.DateTime == "" {
+		d, _ := time.Parse("2006-01-02", t.Date)
+		return name + ";VALUE=DATE", d.Format(icsDate)
+	}
+	d, _ := time.Parse(time.RFC3339, t.DateTime)
+	if loc, err := time.LoadLocation(t.TimeZone); t.TimeZone != "" && err == nil {
+		return name + ";TZID=" + t.TimeZone, d.In(loc).Format(icsLocalTime)
+	}
+	return name, d.UTC().Format(icsDateTime)
+}
+
+// writeICS serializes items as a VCALENDAR with one VEVENT each.
+func writeICS(w io.Writer, items []*calendar.Event, now time.Time) error {
+	iw := &icsWriter{w: w}
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//go-gcal-cli//EN")
+	iw.line("CALSCALE", "GREGORIAN")
+	for _, item := range items {
+		if item.Start == nil || item.End == nil {
+			continue
+		}
+		iw.line("BEGIN", "VEVENT")
+		uid := item.ICalUID
+		if uid == "" {
+			uid = item.Id
+		}
+		iw.line("UID", uid)
+		iw.line("DTSTAMP", now.UTC().Format(icsDateTime))
+		iw.line(icsTime("DTSTART", item.Start))
+		iw.line(icsTime("DTEND", item.End))
+		if item.OriginalStartTime != nil {
+			iw.line(icsTime("RECURRENCE-ID", item.OriginalStartTime))
+		}
+		for _, rule := range item.Recurrence {
+			// Recurrence holds complete RRULE, EXRULE, RDATE and EXDATE lines.
+			if name, value, ok := strings.Cut(rule, ":"); ok {
+				iw.line(name, value)
+			}
+		}
+		iw.line("SUMMARY", icsEscape.Replace(item.Summary))
+		if item.Description != "" {
+			iw.line("DESCRIPTION", icsEscape.Replace(item.Description))
+		}
+		if item.Location != "" {
+			iw.line("LOCATION", icsEscape.Replace(item.Location))
+		}
+		if link := eventLink(item, *linkSource); link != "" {
+			iw.line("URL", link)
+		}
+		if item.Organizer != nil && item.Organizer.Email != "" {
+			iw.line("ORGANIZER", "mailto:"+item.Organizer.Email)
+		}
+		for _, a := range item.Attendees {
+			params := ";PARTSTAT=" + partStat(a.ResponseStatus)
+			if a.DisplayName != "" {
+				// Quoted parameter values cannot contain quotes.
+				params = `;CN="` + strings.ReplaceAll(a.DisplayName, `"`, "'") + `"` + params
+			}
+			iw.line("ATTENDEE"+params, "mailto:"+a.Email)
+		}
+		if item.Status != "" {
+			iw.line("STATUS", strings.ToUpper(item.Status))
+		}
+		iw.line("END", "VEVENT")
+	}
+	iw.line("END", "VCALENDAR")
+	return iw.err
+}
+
+// partStats maps the API's attendee responses to iCalendar PARTSTAT values.
+var partStats = map[string]string{
+	"needsAction": "NEEDS-ACTION",
+	"accepted":    "ACCEPTED",
+	"declined":    "DECLINED",
+	"tentative":   "TENTATIVE",
+}
+
+func partStat(response string) string {
+	if s, ok := partStats[response]; ok {
+		return s
+	}
+	return "NEEDS-ACTION"
+}
+
+// importDryRun is the import command's --dry-run flag.
+var importDryRun bool
+
+var importCmd = &cobra.Command{
+	Use:   "import FILE.ics",
+	Short: "Create the events of an iCalendar file on the first --calendar",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := os.Open(args[0])
+		if err != nil {
+			log.Fatalf("Unable to open %s: %v", args[0], err)
+		}
+		defer f.Close()
+		if !importDryRun {
+			requireScopes(writeScope)
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := runImport(ctx, srv, f, os.Stdout, importDryRun); err != nil {
+			fatal("Unable to import events", err)
+		}
+	},
+}
+
+// icsProperty is one unfolded content line.
+type icsProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// readICS unfolds and splits the content lines of an iCalendar stream.
+func readICS(r io.Reader) ([]icsProperty, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	props := make([]icsProperty, 0, len(lines))
+	for i, line := range lines {
+		head, value, ok := cutUnquoted(line, ':')
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing ':' in %q", i+1, line)
+		}
+		parts := splitUnquoted(head, ';')
+		p := icsProperty{Name: strings.ToUpper(parts[0]), Params: map[string]string{}, Value: value}
+		for _, param := range parts[1:] {
+			k, v, _ := strings.Cut(param, "=")
+			p.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+// cutUnquoted is strings.Cut ignoring separators inside double quotes, as
+// parameter values like CN="Doe, Jane: PM" may contain them.
+func cutUnquoted(s string, sep byte) (string, string, bool) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+func splitUnquoted(s string, sep byte) []string {
+	var parts []string
+	for {
+		part, rest, ok := cutUnquoted(s, sep)
+		parts = append(parts, part)
+		if !ok {
+			return parts
+		}
+		s = rest
+	}
+}
+
+var icsUnescape = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// parseICSTime converts a DTSTART, DTEND or RECURRENCE-ID property. Times
+// that are not in UTC are taken to be in zone.
+func parseICSTime(p icsProperty, zone string) (*calendar.EventDateTime, error) {
+	if p.Params["VALUE"] == "DATE" || len(p.Value) == len(icsDate) {
+		d, err := time.Parse(icsDate, p.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &calendar.EventDateTime{Date: d.Format("2006-01-02")}, nil
+	}
+	if strings.HasSuffix(p.Value, "Z") {
+		t, err := time.Parse(icsDateTime, p.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &calendar.EventDateTime{DateTime: t.Format(time.RFC3339), TimeZone: "UTC"}, nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.ParseInLocation(icsLocalTime, p.Value, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &calendar.EventDateTime{DateTime: t.Format(time.RFC3339), TimeZone: zone}, nil
+}
+
+var icsDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration parses an RFC 5545 duration such as -PT15M or P1DT2H.
+func parseICSDuration(value string) (time.Duration, error) {
+	m := icsDuration.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if n, err := strconv.Atoi(m[i+2]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseICS builds API events from the VEVENTs of an iCalendar file, zone
+// being the target calendar's time zone. Modified instances of a series come
+// after all the masters, which must exist before they can be imported.
+func parseICS(props []icsProperty, zone string) ([]*calendar.Event, error) {
+	var events []*calendar.Event
+	var item *calendar.Event
+	var duration time.Duration
+	inAlarm := false
+	// Zones such as Outlook's Windows names that Go does not know fall back
+	// to the calendar's, with one warning each.
+	unknownZones := map[string]bool{}
+	zoneOf := func(p icsProperty) string {
+		tzid := p.Params["TZID"]
+		if tzid == "" || unknownZones[tzid] {
+			return zone
+		}
+		if _, err := time.LoadLocation(tzid); err != nil {
+			log.Printf("Unknown time zone %q, using %s instead", tzid, zone)
+			unknownZones[tzid] = true
+			return zone
+		}
+		return tzid
+	}
+	for _, p := range props {
+		var err error
+		switch {
+		case p.Name == "BEGIN" && p.Value == "VEVENT":
+			item, duration = &calendar.Event{}, 0
+		case item == nil:
+			// Outside of a VEVENT: VCALENDAR, VTIMEZONE and the like.
+		case p.Name == "BEGIN" && p.Value == "VALARM":
+			inAlarm = true
+		case p.Name == "END" && p.Value == "VALARM":
+			inAlarm = false
+		case inAlarm:
+			if p.Name != "TRIGGER" || p.Params["RELATED"] == "END" {
+				break
+			}
+			if minutes, ok := alarmMinutes(p, item.Start); ok {
+				if item.Reminders == nil {
+					item.Reminders = &calendar.EventReminders{ForceSendFields: []string{"UseDefault"}}
+				}
+				item.Reminders.Overrides = append(item.Reminders.Overrides, &calendar.EventReminder{Method: "popup", Minutes: minutes})
+			}
+		case p.Name == "END" && p.Value == "VEVENT":
+			if item.Start == nil {
+				return nil, fmt.Errorf("event %q has no DTSTART", item.Summary)
+			}
+			if item.End == nil {
+				item.End = endAfter(item.Start, duration)
+			}
+			events = append(events, item)
+			item = nil
+		case p.Name == "UID":
+			item.ICalUID = p.Value
+		case p.Name == "SUMMARY":
+			item.Summary = icsUnescape.Replace(p.Value)
+		case p.Name == "DESCRIPTION":
+			item.Description = icsUnescape.Replace(p.Value)
+		case p.Name == "LOCATION":
+			item.Location = icsUnescape.Replace(p.Value)
+		case p.Name == "DTSTART":
+			item.Start, err = parseICSTime(p, zoneOf(p))
+		case p.Name == "DTEND":
+			item.End, err = parseICSTime(p, zoneOf(p))
+		case p.Name == "RECURRENCE-ID":
+			item.OriginalStartTime, err = parseICSTime(p, zoneOf(p))
+		case p.Name == "DURATION":
+			duration, err = parseICSDuration(p.Value)
+		case p.Name == "RRULE", p.Name == "EXRULE", p.Name == "RDATE", p.Name == "EXDATE":
+			line := p.Name
+			for k, v := range p.Params {
+				line += ";" + k + "=" + v
+			}
+			item.Recurrence = append(item.Recurrence, line+":"+p.Value)
+		case p.Name == "ATTENDEE":
+			a := &calendar.EventAttendee{
+				Email:       strings.TrimPrefix(strings.TrimPrefix(p.Value, "mailto:"), "MAILTO:"),
+				DisplayName: p.Params["CN"],
+				Optional:    p.Params["ROLE"] == "OPT-PARTICIPANT",
+			}
+			item.Attendees = append(item.Attendees, a)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Name, err)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OriginalStartTime == nil && events[j].OriginalStartTime != nil
+	})
+	return events, nil
+}
+
+// alarmMinutes converts a VALARM's TRIGGER into the minutes before start of
+// a popup reminder. Absolute triggers need a timed start to be relative to;
+// triggers after the start and ones that do not parse have no reminder.
+func alarmMinutes(p icsProperty, start *calendar.EventDateTime) (int64, bool) {
+	var d time.Duration
+	if p.Params["VALUE"] == "DATE-TIME" {
+		if start == nil || start.DateTime == "" {
+			return 0, false
+		}
+		at, err := time.Parse(icsDateTime, p.Value)
+		if err != nil {
+			return 0, false
+		}
+		startTime, _ := time.Parse(time.RFC3339, start.DateTime)
+		d = at.Sub(startTime)
+	} else {
+		var err error
+		if d, err = parseICSDuration(p.Value); err != nil {
+			return 0, false
+		}
+	}
+	if d > 0 {
+		return 0, false
+	}
+	return int64(-d / time.Minute), true
+}
+
+// endAfter returns the end of an event lasting d from start. Without a
+// duration, all-day events last a day and timed events are instantaneous.
+func endAfter(start *calendar.EventDateTime, d time.Duration) *calendar.EventDateTime {
+	if start.Date != "" {
+		t, _ := time.Parse("2006-01-02", start.Date)
+		days := int(d / (24 * time.Hour))
+		if days < 1 {
+			days = 1
+		}
+		return &calendar.EventDateTime{Date: t.AddDate(0, 0, days).Format("2006-01-02")}
+	}
+	t, _ := time.Parse(time.RFC3339, start.DateTime)
+	return &calendar.EventDateTime{DateTime: t.Add(d).Format(time.RFC3339), TimeZone: start.TimeZone}
+}
+
+// runImport creates the events in r on the first selected calendar. Events
+// with a UID go through Events.Import, so importing a file twice updates
+// instead of duplicating them.
+func runImport(ctx context.Context, srv *calendar.Service, r io.Reader, w io.Writer, dryRun bool) error {
+	id := calendarIDs()[0]
+	cal, err := srv.Calendars.Get(id).Context(ctx).Do(callOptions()...)
+	if err != nil {
+		return err
+	}
+	props, err := readICS(r)
+	if err != nil {
+		return err
+	}
+	items, err := parseICS(props, cal.TimeZone)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		when := eventWhen(item)
+		if dryRun {
+			fmt.Fprintf(w, "Would create %s  %s\n", when, item.Summary)
+			continue
+		}
+		var created *calendar.Event
+		if item.ICalUID != "" {
+			created, err = srv.Events.Import(id, item).Context(ctx).Do(callOptions()...)
+		} else {
+			created, err = srv.Events.Insert(id, item).Context(ctx).Do(callOptions()...)
+		}
+		if err != nil {
+			return fmt.Errorf("%q: %w", item.Summary, scopeError(err))
+		}
+		fmt.Fprintf(w, "Created %s  %s (%s)\n", when, created.Summary, created.Id)
+	}
+	if dryRun {
+		fmt.Fprintf(w, "%d events would be created on %s.\n", len(items), id)
+	}
+	return nil
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "only list the events that would be created")
+	rootCmd.AddCommand(exportCmd, importCmd)
+}
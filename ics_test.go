@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestICSRoundTrip(t *testing.T) {
+	now := time.Date(2024, 6, 5, 10, 30, 0, 0, time.UTC)
+	master := &calendar.Event{
+		ICalUID:    "standup@example.com",
+		Summary:    "Standup; daily, short",
+		Start:      &calendar.EventDateTime{DateTime: "2024-06-03T09:00:00+02:00", TimeZone: "Europe/Berlin"},
+		End:        &calendar.EventDateTime{DateTime: "2024-06-03T09:15:00+02:00", TimeZone: "Europe/Berlin"},
+		Recurrence: []string{"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+	}
+	moved := &calendar.Event{
+		ICalUID:           "standup@example.com",
+		Summary:           "Standup (moved)",
+		Start:             &calendar.EventDateTime{DateTime: "2024-06-05T10:00:00+02:00", TimeZone: "Europe/Berlin"},
+		End:               &calendar.EventDateTime{DateTime: "2024-06-05T10:15:00+02:00", TimeZone: "Europe/Berlin"},
+		OriginalStartTime: &calendar.EventDateTime{DateTime: "2024-06-05T09:00:00+02:00", TimeZone: "Europe/Berlin"},
+	}
+	holiday := &calendar.Event{
+		ICalUID: "holiday@example.com",
+		Summary: "Holiday",
+		Start:   &calendar.EventDateTime{Date: "2024-06-10"},
+		End:     &calendar.EventDateTime{Date: "2024-06-11"},
+	}
+
+	var b bytes.Buffer
+	// The modified instance comes first, as the master must still be
+	// imported before it.
+	if err := writeICS(&b, []*calendar.Event{moved, master, holiday}, now); err != nil {
+		t.Fatal(err)
+	}
+	props, err := readICS(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseICS(props, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*calendar.Event{master, holiday, moved}
+	if len(got) != len(want) {
+		t.Fatalf("parsed %d events, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.ICalUID != w.ICalUID || g.Summary != w.Summary {
+			t.Errorf("event %d = %q %q, want %q %q", i, g.ICalUID, g.Summary, w.ICalUID, w.Summary)
+		}
+		if !sameTime(g.Start, w.Start) || !sameTime(g.End, w.End) {
+			t.Errorf("%q runs %v to %v, want %v to %v", w.Summary, g.Start, g.End, w.Start, w.End)
+		}
+		if (g.OriginalStartTime == nil) != (w.OriginalStartTime == nil) ||
+			g.OriginalStartTime != nil && !sameTime(g.OriginalStartTime, w.OriginalStartTime) {
+			t.Errorf("%q has original start %v, want %v", w.Summary, g.OriginalStartTime, w.OriginalStartTime)
+		}
+		if strings.Join(g.Recurrence, "\n") != strings.Join(w.Recurrence, "\n") {
+			t.Errorf("%q recurs %q, want %q", w.Summary, g.Recurrence, w.Recurrence)
+		}
+	}
+}
+
+// sameTime reports whether a and b are the same day or instant in the same
+// zone, however the offset is written.
+func sameTime(a, b *calendar.EventDateTime) bool {
+	if a.Date != "" || b.Date != "" {
+		return a.Date == b.Date
+	}
+	ta, _ := time.Parse(time.RFC3339, a.DateTime)
+	tb, _ := time.Parse(time.RFC3339, b.DateTime)
+	return ta.Equal(tb) && a.TimeZone == b.TimeZone
+}
+
+func TestParseICSAlarmsAndZones(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:review@example.com",
+		"SUMMARY:Review",
+		"DTSTART;TZID=W. Europe Standard Time:20240605T090000",
+		"DURATION:PT1H",
+		"BEGIN:VALARM",
+		"TRIGGER;VALUE=DATE-TIME:20240605T084500Z",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT1H",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"TRIGGER:PT5M",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	props, err := readICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := parseICS(props, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("parsed %d events, want 1", len(events))
+	}
+	item := events[0]
+	want := &calendar.EventDateTime{DateTime: "2024-06-05T09:00:00Z", TimeZone: "UTC"}
+	if !sameTime(item.Start, want) {
+		t.Errorf("Start = %v, want %v", item.Start, want)
+	}
+	var minutes []int64
+	if item.Reminders != nil {
+		for _, r := range item.Reminders.Overrides {
+			minutes = append(minutes, r.Minutes)
+		}
+	}
+	if len(minutes) != 2 || minutes[0] != 15 || minutes[1] != 60 {
+		t.Errorf("reminders = %v, want [15 60]", minutes)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2/google"
+)
+
+const credentialsHelp = `go-gcal-cli needs an OAuth client to talk to Google Calendar:
+
+  1. Open https://console.cloud.google.com/apis/credentials
+  2. Enable the Google Calendar API for your project
+  3. Create credentials > OAuth client ID > Desktop app
+  4. Download the client JSON file
+`
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up credentials, authorization and defaults interactively",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runInit(stdin, os.Stdout); err != nil {
+			fatal("Unable to complete setup", err)
+		}
+	},
+}
+
+// ask prints question with its default and returns the answer, or def when
+// the user just presses enter.
+func ask(r *bufio.Reader, w io.Writer, question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w, "%s: ", question)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return def, nil
+}
+
+// runInit walks through the first-run setup and writes the config file.
+func runInit(r *bufio.Reader, w io.Writer) error {
+	if err := installCredentials(r, w); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nAuthorizing with Google...")
+	srv := newService()
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	list, err := srv.CalendarList.List().Context(ctx).Do(callOptions()...)
+	if err != nil {
+		return fmt.Errorf("listing calendars: %w", err)
+	}
+	fmt.Fprintln(w, "\nYour calendars:")
+	choice, zone := 0, ""
+	for i, c := range list.Items {
+		fmt.Fprintf(w, "  %d. %s (%s)\n", i+1, c.Summary, c.Id)
+		if c.Primary {
+			choice, zone = i+1, c.TimeZone
+		}
+	}
+	answer, err := ask(r, w, "Default calendar", strconv.Itoa(choice))
+	if err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(list.Items) {
+		return fmt.Errorf("no calendar numbered %q", answer)
+	}
+	calendar := list.Items[n-1]
+
+	if zone == "" {
+		zone = calendar.TimeZone
+	}
+	if zone == "" {
+		zone = time.Local.String()
+	}
+	zone, err = ask(r, w, "Time zone", zone)
+	if err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(zone); err != nil {
+		return err
+	}
+
+	s, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	s["calendar"] = []string{calendar.Id}
+	s["tz"] = zone
+	if err := saveSettings(s); err != nil {
+		return err
+	}
+	path, _ := configFilePath()
+	fmt.Fprintf(w, "\nWrote %s. You're all set; run go-gcal-cli to see what's next.\n", path)
+	return nil
+}
+
+// installCredentials copies a downloaded OAuth client file into the config
+// directory unless one is already in place.
+func installCredentials(r *bufio.Reader, w io.Writer) error {
+	if path := configPath(credentialsFile); fileExists(path) {
+		fmt.Fprintf(w, "Using OAuth client credentials from %s\n", path)
+		return nil
+	}
+	fmt.Fprint(w, credentialsHelp)
+	src, err := ask(r, w, "\nPath to the downloaded JSON file", "")
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if _, err := google.ConfigFromJSON(b); err != nil {
+		return fmt.Errorf("%s is not an OAuth client file: %w", src, err)
+	}
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	dst := filepath.Join(dir, credentialsFile)
+	if err := os.WriteFile(dst, b, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Saved credentials to %s\n", dst)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The invites command's flags.
+var (
+	invitesDays        int
+	invitesInteractive bool
+)
+
+// invitesAnswers maps the keys of the interactive prompt to responses.
+var invitesAnswers = map[string]string{"a": "accept", "d": "decline", "t": "tentative"}
+
+// pendingInvites returns the invitations among items the user has not
+// answered yet.
+func pendingInvites(items []*calendar.Event) []*calendar.Event {
+	var pending []*calendar.Event
+	for _, item := range items {
+		if selfResponse(item) == "needsAction" && !isRecurringMaster(item) {
+			pending = append(pending, item)
+		}
+	}
+	return pending
+}
+
+// inviteClash returns the first event among items the user has committed
+// to that overlaps invite, or nil.
+func inviteClash(invite *calendar.Event, items []*calendar.Event) *calendar.Event {
+	start, end, ok := eventTimes(invite)
+	if !ok {
+		return nil
+	}
+	for _, item := range items {
+		if item == invite || sameEvent(item, invite) || !blocksTime(item) || !acceptedBySelf(item) {
+			continue
+		}
+		if s, e, ok := eventTimes(item); ok && s.Before(end) && e.After(start) {
+			return item
+		}
+	}
+	return nil
+}
+
+// organizerName is who sent invite.
+func organizerName(item *calendar.Event) string {
+	switch {
+	case item.Organizer == nil:
+		return ""
+	case item.Organizer.DisplayName != "":
+		return item.Organizer.DisplayName
+	}
+	return item.Organizer.Email
+}
+
+// writeInvites lists the pending invitations, marking the ones that clash
+// with conflictMarker.
+func writeInvites(w io.Writer, pending, items []*calendar.Event) error {
+	if ok, err := writeEvents(w, pending); ok {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !*noHeader {
+		fmt.Fprintln(tw, "  WHEN\tSUMMARY\tFROM\tID")
+	}
+	for _, item := range pending {
+		marker := " "
+		if inviteClash(item, items) != nil {
+			marker = conflictMarker
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\n", marker, eventWhen(item), item.Summary, organizerName(item), item.Id)
+	}
+	return tw.Flush()
+}
+
+// triageInvites asks about each pending invitation in turn and sends the
+// answers. It stops early on "q".
+func triageInvites(ctx context.Context, srv *calendar.Service, pending, items []*calendar.Event) error {
+	for i, item := range pending {
+		fmt.Printf("\n%d/%d  %s  %s\n", i+1, len(pending), formatEventTime(item), item.Summary)
+		if from := organizerName(item); from != "" {
+			fmt.Printf("      from %s\n", from)
+		}
+		if clash := inviteClash(item, items); clash != nil {
+			fmt.Printf("      %s overlaps %s  %s\n", conflictMarker, formatEventTime(clash), clash.Summary)
+		}
+		answer, err := ask(stdin, os.Stdout, "[a]ccept, [d]ecline, [t]entative, [s]kip or [q]uit", "s")
+		if err != nil {
+			return err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "q" {
+			return nil
+		}
+		response, ok := invitesAnswers[answer]
+		if !ok {
+			continue
+		}
+		if _, err := events.RSVP(ctx, srv, eventCalendar(item), item, response, "", callOptions()...); err != nil {
+			return scopeError(err)
+		}
+		fmt.Printf("      %s\n", events.Responses[response])
+	}
+	return nil
+}
+
+var invitesCmd = &cobra.Command{
+	Use:   "invites",
+	Short: "List the invitations you have not answered",
+	Long: fmt.Sprintf("List the invitations over the next --days days, %d by default, or from --from to --to, that "+
+		"still wait for your answer. %s marks the ones that overlap an event you have accepted.\n\n"+
+		"With --interactive each one is shown in turn to accept, decline, mark tentative or skip.", 30, conflictMarker),
+	Example: `  go-gcal-cli invites
+  go-gcal-cli invites -i --days 7`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if invitesDays < 1 {
+			usageFatalf("--days must be at least 1")
+		}
+		now := time.Now()
+		from, to := now, startOfDay(now).AddDate(0, 0, invitesDays+1)
+		if *fromFlag != "" || *toFlag != "" {
+			var err error
+			if from, to, err = freebusyWindow(now); err != nil {
+				usageFatalf("Invalid window: %v", err)
+			}
+		}
+		if invitesInteractive {
+			requireScopes(writeScope)
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		fetched, err := fetchEvents(ctx, srv, from, to)
+		if err != nil {
+			fatal("Unable to retrieve events", err)
+		}
+		warnStale(os.Stderr)
+		pending := pendingInvites(fetched.Items)
+		if len(pending) == 0 {
+			fmt.Println("No invitations to answer.")
+			return
+		}
+		if !invitesInteractive {
+			if err := writeInvites(os.Stdout, pending, fetched.Items); err != nil {
+				fatal("Unable to list invitations", err)
+			}
+			return
+		}
+		if err := triageInvites(ctx, srv, pending, fetched.Items); err != nil {
+			fatal("Unable to respond", err)
+		}
+	},
+}
+
+func init() {
+	f := invitesCmd.Flags()
+	f.IntVar(&invitesDays, "days", 30, "number of days ahead to look")
+	f.BoolVarP(&invitesInteractive, "interactive", "i", false, "go through the invitations one by one and answer them")
+	rootCmd.AddCommand(invitesCmd)
+}
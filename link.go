@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The link command's flags.
+var (
+	linkSearch string
+	linkHTML   bool
+	linkPrint  bool
+)
+
+// shareLink is the link to hand out for item: its meeting link, or with
+// --html or when it has none, its page in Google Calendar.
+func shareLink(item *calendar.Event) string {
+	if !linkHTML {
+		if link := eventLink(item, *linkSource); link != "" {
+			return link
+		}
+	}
+	return item.HtmlLink
+}
+
+// linkedEvent finds the event the link command is about: the one given by ID
+// or --search, or else the meeting that is on or comes next.
+func linkedEvent(ctx context.Context, srv *calendar.Service, id string, now time.Time) (*calendar.Event, error) {
+	if id != "" || linkSearch != "" {
+		_, item, err := resolveEvent(ctx, srv, id, linkSearch)
+		return item, err
+	}
+	item, _, err := findUpcoming(ctx, srv, now, func(items []*calendar.Event) *calendar.Event {
+		if meetings := joinableMeetings(items, now, math.MaxInt64); len(meetings) > 0 {
+			return meetings[0]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	warnStale(os.Stderr)
+	if item == nil {
+		return nil, errors.New("no upcoming meeting has a link")
+	}
+	return item, nil
+}
+
+var linkCmd = &cobra.Command{
+	Use:   "link [EVENT-ID]",
+	Short: "Copy an event's meeting link to the clipboard",
+	Long: "Copy the meeting link of an event, found by ID or with --search by title, to the clipboard. Without either " +
+		"it is the meeting in progress or the next one with a link.\n\n" +
+		"Events without a meeting link, and all of them with --html, give their Google Calendar page instead. The " +
+		"clipboard is set with pbcopy, clip, wl-copy, xclip or xsel, whichever the platform has.",
+	Example: `  go-gcal-cli link
+  go-gcal-cli link --search standup --html`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		item, err := linkedEvent(ctx, srv, id, time.Now())
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		link := shareLink(item)
+		if link == "" {
+			log.Fatalf("%s has no link", item.Summary)
+		}
+		if linkPrint {
+			fmt.Println(link)
+			return
+		}
+		if err := copyToClipboard(link); err != nil {
+			fatal("Unable to copy the link", err)
+		}
+		fmt.Printf("Copied %s: %s\n", item.Summary, link)
+	},
+}
+
+func init() {
+	f := linkCmd.Flags()
+	f.StringVar(&linkSearch, "search", "", "find the event by title instead of ID")
+	f.BoolVar(&linkHTML, "html", false, "copy the event's Google Calendar page instead of its meeting link")
+	f.BoolVar(&linkPrint, "print", false, "print the link instead of copying it")
+	rootCmd.AddCommand(linkCmd)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// meetingPatterns match the join links of video meeting services, which
+// invitations from outside Google Calendar carry in the location or the
+// description.
+var meetingPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^https://([\w-]+\.)*(zoom\.us|zoomgov\.com)/(j|my|s|w|wc)/`),
+	regexp.MustCompile(`^https://teams\.(microsoft|live)\.com/(l/meetup-join|meet)/`),
+	regexp.MustCompile(`^https://([\w-]+\.)*webex\.com/`),
+	regexp.MustCompile(`^https://meet\.jit\.si/`),
+	regexp.MustCompile(`^https://meet\.google\.com/`),
+}
+
+// linkSources are the accepted --link-source values. auto tries the others
+// in this order.
+var linkSources = []string{"auto", "conference", "hangout", "location", "description"}
+
+func validLinkSource(source string) error {
+	for _, s := range linkSources {
+		if s == source {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown link source %q; use one of %s", source, strings.Join(linkSources, ", "))
+}
+
+// conferenceLink returns the video entry point of the event's conference
+// data, if any.
+func conferenceLink(item *calendar.Event) string {
+	if item.ConferenceData == nil {
+		return ""
+	}
+	for _, ep := range item.ConferenceData.EntryPoints {
+		if ep.EntryPointType == "video" && ep.Uri != "" {
+			return ep.Uri
+		}
+	}
+	return ""
+}
+
+// textLinks returns the URLs in text, without the punctuation that often
+// follows them in prose.
+func textLinks(text string) []string {
+	links := urlPattern.FindAllString(text, -1)
+	for i, link := range links {
+		links[i] = strings.TrimRight(link, ".,;:!?)]")
+	}
+	return links
+}
+
+// meetingLink returns the first link in text to a known meeting service.
+func meetingLink(text string) string {
+	for _, link := range textLinks(text) {
+		for _, p := range meetingPatterns {
+			if p.MatchString(link) {
+				return link
+			}
+		}
+	}
+	return ""
+}
+
+// textLink prefers a meeting link in text over its first URL.
+func textLink(text string) string {
+	if link := meetingLink(text); link != "" {
+		return link
+	}
+	if links := textLinks(text); len(links) > 0 {
+		return links[0]
+	}
+	return ""
+}
+
+// eventLink picks the joinable link shown for an event according to source.
+// auto falls back from the conference data and the Meet link to a Zoom,
+// Teams, Webex or Jitsi link in the location or description, and only then
+// to any other URL there.
+func eventLink(item *calendar.Event, source string) string {
+	switch source {
+	case "conference":
+		return conferenceLink(item)
+	case "hangout":
+		return item.HangoutLink
+	case "location":
+		return textLink(item.Location)
+	case "description":
+		return textLink(item.Description)
+	}
+	for _, link := range []string{
+		conferenceLink(item), item.HangoutLink,
+		meetingLink(item.Location), meetingLink(item.Description),
+		textLink(item.Location), textLink(item.Description),
+	} {
+		if link != "" {
+			return link
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// upcomingEvent returns the first timed event that starts after now, or nil.
+func upcomingEvent(items []*calendar.Event, now time.Time) *calendar.Event {
+	for _, item := range items {
+		startTime, _, ok := eventTimes(item)
+		if ok && !isRecurringMaster(item) && startTime.After(now) {
+			return item
+		}
+	}
+	return nil
+}
+
+// dayStats counts the timed events that start on the same day as day and
+// sums their duration.
+func dayStats(items []*calendar.Event, day time.Time) (int, time.Duration) {
+	from := startOfDay(day)
+	to := from.AddDate(0, 0, 1)
+	count, total := 0, time.Duration(0)
+	for _, item := range items {
+		startTime, endTime, ok := eventTimes(item)
+		if !ok || isRecurringMaster(item) {
+			continue
+		}
+		startTime = startTime.In(day.Location())
+		if startTime.Before(from) || !startTime.Before(to) {
+			continue
+		}
+		count++
+		total += endTime.Sub(startTime)
+	}
+	return count, total
+}
+
+// writeMetrics emits gauges in the Prometheus text exposition format, suitable
+// for the node_exporter textfile collector. Metric names are part of the
+// tool's interface; add new ones rather than renaming existing ones.
+func writeMetrics(w io.Writer, items []*calendar.Event, now time.Time) error {
+	count, total := dayStats(items, now)
+	next := -1.0
+	if item := upcomingEvent(items, now); item != nil {
+		startTime, _, _ := eventTimes(item)
+		next = startTime.Sub(now).Seconds()
+	}
+
+	_, err := fmt.Fprintf(w, `# HELP gcal_meetings_today Number of timed meetings starting today.
+# TYPE gcal_meetings_today gauge
+gcal_meetings_today %d
+# HELP gcal_next_meeting_seconds Seconds until the next meeting starts, or -1 if there is none.
+# TYPE gcal_next_meeting_seconds gauge
+gcal_next_meeting_seconds %.0f
+# HELP gcal_meeting_hours_today Total hours of timed meetings starting today.
+# TYPE gcal_meeting_hours_today gauge
+gcal_meeting_hours_today %g
+`, count, next, total.Hours())
+	return err
+}
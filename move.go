@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+)
+
+// The move command's flags. --to shadows the global window flag of the
+// same name.
+var (
+	moveSearch string
+	moveTo     string
+	moveYes    bool
+	moveNotify bool
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move [EVENT-ID] --to CALENDAR",
+	Short: "Move an event to another calendar",
+	Long: "Move an event, found by ID or with --search by title, to the calendar given by --to, keeping its ID, " +
+		"guests and answers.\n\n" +
+		"Occurrences of a recurring event cannot move on their own, so the whole series moves after " +
+		"confirmation. Only the organizer's copy of an event can be moved.",
+	Example: `  go-gcal-cli move abc123 --to personal
+  go-gcal-cli move --search "dentist" --calendar work --to home`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		if moveTo == "" {
+			usageFatalf("--to is required")
+		}
+		requireScopes(writeScope)
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		entries, _, err := cachedCalendars(ctx, srv, false)
+		if err != nil {
+			fatal("Unable to list calendars", err)
+		}
+		calendarList = entries
+		destination, err := resolveCalendar(entries, moveTo)
+		if err != nil {
+			fatal("Invalid --to", &UsageError{err})
+		}
+		calendarID, item, err := resolveEvent(ctx, srv, id, moveSearch)
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		// calendarEntry also sees through the primary alias.
+		if entry := calendarEntry(destination); destination == calendarID || entry != nil && entry == calendarEntry(calendarID) {
+			log.Fatalf("%s is already on %s", item.Summary, calendarName(destination))
+		}
+		eventID := item.Id
+		if item.RecurringEventId != "" {
+			fmt.Printf("%s  %s\n", eventWhen(item), item.Summary)
+			if !moveYes {
+				ok, err := confirm("Move every occurrence of this recurring event?")
+				if err != nil {
+					fatal("Unable to read confirmation", err)
+				}
+				if !ok {
+					fmt.Println("Nothing moved.")
+					return
+				}
+			}
+			eventID = item.RecurringEventId
+		}
+		moved, err := events.Move(ctx, srv, calendarID, eventID, destination, moveNotify, callOptions()...)
+		if err != nil {
+			fatal("Unable to move event", scopeError(err))
+		}
+		fmt.Printf("Moved %s  %s from %s to %s\n", eventWhen(moved), moved.Summary, calendarName(calendarID), calendarName(destination))
+	},
+}
+
+func init() {
+	f := moveCmd.Flags()
+	f.StringVar(&moveSearch, "search", "", "find the event by title instead of ID")
+	f.StringVar(&moveTo, "to", "", "calendar ID or name to move the event to")
+	f.BoolVarP(&moveYes, "yes", "y", false, "do not ask before moving a whole series")
+	f.BoolVar(&moveNotify, "notify", false, "email the attendees about the move")
+	rootCmd.AddCommand(moveCmd)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// nextWithin is the next command's --within flag.
+var nextWithin time.Duration
+
+var nextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Print the next meeting on one line; exit 1 if there is none",
+	Long: "Print the next meeting's summary, start time, minutes until it starts and link on one line.\n\n" +
+		"The exit status is 0 if a meeting starts within --within (or at all, if unset) and 1 otherwise, " +
+		"so that shell prompts and scripts can test for it. --format replaces the line.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		found, err := runNext(ctx, srv, os.Stdout, time.Now())
+		if err != nil {
+			fatal("Unable to find the next meeting", err)
+		}
+		if !found {
+			os.Exit(1)
+		}
+	},
+}
+
+// nextMeetingEvent returns the first event starting after now that the user
+// has not declined, or nil.
+func nextMeetingEvent(items []*calendar.Event, now time.Time) *calendar.Event {
+	var accepted []*calendar.Event
+	for _, item := range items {
+		if !declinedBySelf(item) {
+			accepted = append(accepted, item)
+		}
+	}
+	return upcomingEvent(accepted, now)
+}
+
+// runNext prints the next meeting and reports whether there is one within
+// --within.
+func runNext(ctx context.Context, srv *calendar.Service, w io.Writer, now time.Time) (bool, error) {
+	// Meetings that are already on and ones the user declined are passed
+	// over.
+	item, _, err := findUpcoming(ctx, srv, now, func(items []*calendar.Event) *calendar.Event {
+		return nextMeetingEvent(items, now)
+	})
+	if err != nil {
+		return false, err
+	}
+	warnStale(os.Stderr)
+	if item == nil {
+		return false, nil
+	}
+	startTime, _, _ := eventTimes(item)
+	until := startTime.Sub(now)
+	if nextWithin > 0 && until > nextWithin {
+		return false, nil
+	}
+
+	if *formatFlag != "" {
+		return true, writeTemplate(w, []*calendar.Event{item}, *formatFlag)
+	}
+	fields := []string{item.Summary, startTime.Local().Format("15:04"), fmt.Sprintf("in %dm", int(until.Round(time.Minute).Minutes()))}
+	if link := eventLink(item, *linkSource); link != "" {
+		fields = append(fields, link)
+	}
+	_, err = fmt.Fprintln(w, strings.Join(fields, " "))
+	return true, err
+}
+
+func init() {
+	nextCmd.Flags().DurationVar(&nextWithin, "within", 0, "only count meetings starting this soon (e.g. 15m)")
+	rootCmd.AddCommand(nextCmd)
+}
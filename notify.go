@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// notification is a desktop notification. Clicking it opens URL where the
+// platform allows; elsewhere the URL is shown in the body.
+type notification struct {
+	Title string
+	Body  string
+	URL   string
+}
+
+// notify shows n with the platform's notifier: notify-send on Linux and the
+// BSDs, terminal-notifier or osascript on macOS, a toast on Windows. It
+// returns once the notification is shown, not when it is clicked.
+func notify(n notification) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return notifyDarwin(n)
+	case "windows":
+		return notifyWindows(n)
+	default:
+		return notifySend(n)
+	}
+}
+
+// notifySend uses notify-send's actions to offer a Join button, falling back
+// to a plain notification on versions without --action.
+func notifySend(n notification) error {
+	if n.URL == "" {
+		return exec.Command("notify-send", "--app-name="+appName, n.Title, n.Body).Run()
+	}
+	cmd := exec.Command("notify-send", "--app-name="+appName, "--action=open=Join", "--wait", n.Title, n.Body)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		if err == nil && strings.TrimSpace(out.String()) == "open" {
+			openBrowser(n.URL)
+		}
+		done <- err
+	}()
+	// An old notify-send fails on --action straight away; a new one blocks
+	// until the notification is dismissed.
+	select {
+	case err := <-done:
+		if err != nil {
+			return exec.Command("notify-send", "--app-name="+appName, n.Title, n.Body+"\n"+n.URL).Run()
+		}
+	default:
+	}
+	return nil
+}
+
+func notifyDarwin(n notification) error {
+	if path, err := exec.LookPath("terminal-notifier"); err == nil {
+		args := []string{"-title", n.Title, "-message", n.Body, "-group", appName}
+		if n.URL != "" {
+			args = append(args, "-open", n.URL)
+		}
+		return exec.Command(path, args...).Run()
+	}
+	body := n.Body
+	if n.URL != "" {
+		body += " " + n.URL
+	}
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(n.Title))
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// toastScript shows a toast whose click launches the URL, through the
+// WinRT notification API PowerShell can reach without extra modules.
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml($env:GCAL_TOAST)
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('go-gcal-cli').Show($toast)
+`
+
+func notifyWindows(n notification) error {
+	launch := ""
+	if n.URL != "" {
+		launch = fmt.Sprintf(` activationType="protocol" launch="%s"`, xmlEscape(n.URL))
+	}
+	toast := fmt.Sprintf(`<toast%s><visual><binding template="ToastGeneric"><text>%s</text><text>%s</text></binding></visual></toast>`,
+		launch, xmlEscape(n.Title), xmlEscape(n.Body))
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	// The XML goes through the environment to stay clear of PowerShell
+	// quoting.
+	cmd.Env = append(cmd.Environ(), "GCAL_TOAST="+toast)
+	return cmd.Run()
+}
+
+// xmlEscape escapes s for use in XML text and attribute values.
+var xmlEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace
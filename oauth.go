@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// randomState returns an unguessable value for the OAuth state parameter.
+func randomState() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "state-token"
+	}
+	return hex.EncodeToString(b)
+}
+
+// errNoLoopback means no local port could be opened for the redirect, so the
+// caller should fall back to pasting the code manually.
+var errNoLoopback = errors.New("unable to listen for the OAuth redirect")
+
+// tokenFromCallback runs the loopback redirect flow: it listens on an
+// ephemeral localhost port, sends the user to the consent page, waits for
+// Google to redirect back with the authorization code and exchanges it.
+func tokenFromCallback(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoLoopback, err)
+	}
+	defer listener.Close()
+
+	// Copy the config so the redirect URL does not leak into the caller's.
+	cfg := *config
+	cfg.RedirectURL = fmt.Sprintf("http://%s/", listener.Addr())
+	state := randomState()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "State mismatch, please retry.", http.StatusBadRequest)
+			return
+		case q.Get("error") != "":
+			fmt.Fprintln(w, "Authorization failed, you can close this window.")
+			results <- result{err: fmt.Errorf("authorization denied: %s", q.Get("error"))}
+		case q.Get("code") == "":
+			http.Error(w, "Missing authorization code.", http.StatusBadRequest)
+			return
+		default:
+			fmt.Fprintln(w, "Authorization complete, you can close this window.")
+			results <- result{code: q.Get("code")}
+		}
+	})}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	authURL := cfg.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	fmt.Printf("Opening your browser to authorize access. If it does not open, visit:\n%v\n", authURL)
+	if err := openBrowser(authURL); err != nil {
+		fmt.Printf("Unable to open browser: %v\n", err)
+	}
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return cfg.Exchange(ctx, res.code)
+	case <-ctx.Done():
+		return nil, errors.New("timed out waiting for authorization")
+	}
+}
+
+// tokenFromDevice runs the device authorization flow for machines without a
+// browser: it prints a short code to enter on another device and polls until
+// the user approves.
+func tokenFromDevice(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	cfg := *config
+	if cfg.Endpoint.DeviceAuthURL == "" {
+		cfg.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	}
+	da, err := cfg.DeviceAuth(ctx, oauth2.AccessTypeOffline)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("On any device, go to %v and enter the code: %v\n", da.VerificationURI, da.UserCode)
+	return cfg.DeviceAccessToken(ctx, da)
+}
+
+// authFlows are the accepted --auth-flow values.
+var authFlows = []string{"browser", "device", "manual"}
+
+func validAuthFlow(flow string) error {
+	for _, f := range authFlows {
+		if f == flow {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown auth flow %q; use one of %s", flow, strings.Join(authFlows, ", "))
+}
+
+// serviceAccountClient authorizes as the service account in keyFile. With
+// subject set it uses domain-wide delegation to act as that user.
+func serviceAccountClient(keyFile, subject string) *http.Client {
+	b, err := os.ReadFile(keyFile)
+	if err != nil {
+		fatal("Unable to read service account key", err)
+	}
+	config, err := google.JWTConfigFromJSON(b, requestedScopes()...)
+	if err != nil {
+		fatal("Unable to parse service account key", err)
+	}
+	config.Subject = subject
+	return config.Client(context.Background())
+}
+
+// adcAvailable reports whether Application Default Credentials can be found,
+// from $GOOGLE_APPLICATION_CREDENTIALS, gcloud or the metadata server.
+func adcAvailable() bool {
+	_, err := google.FindDefaultCredentials(context.Background(), requestedScopes()...)
+	return err == nil
+}
+
+// adcClient authorizes with Application Default Credentials. User
+// credentials from gcloud must have been created with the calendar scope,
+// e.g. gcloud auth application-default login --scopes=...
+func adcClient() *http.Client {
+	client, err := google.DefaultClient(context.Background(), requestedScopes()...)
+	if err != nil {
+		fatal("Unable to use Application Default Credentials", err)
+	}
+	return client
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The ooo and focus commands' flags.
+var (
+	oooTitle     string
+	oooDecline   string
+	oooMessage   string
+	focusTitle   string
+	focusDecline string
+	focusMessage string
+	focusDay     string
+	focusToday   string
+	focusDND     bool
+)
+
+// insertBlock creates item, an out-of-office or focus time event, on the
+// first --calendar.
+func insertBlock(item *calendar.Event) {
+	requireScopes(writeScope)
+	srv := newService()
+	ctx, cancel := requestContext()
+	defer cancel()
+	created, err := events.Insert(ctx, srv, calendarIDs()[0], item, callOptions()...)
+	if err != nil {
+		fatal("Unable to create event", scopeError(err))
+	}
+	printCreated(created)
+}
+
+var oooCmd = &cobra.Command{
+	Use:   "ooo",
+	Short: "Mark yourself out of office",
+	Long: "Create an out-of-office event from --from until --to, when you are back. Meetings in that time are " +
+		"declined for you as --decline says, with --message as the reason: all of them (the default), only " +
+		"invitations that arrive later, or none.\n\n" +
+		"Out-of-office events can only be created on a primary calendar.",
+	Example: `  go-gcal-cli ooo --from fri --to mon --message "On PTO"
+  go-gcal-cli ooo --from "today 13:00" --to tomorrow --decline new`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if *fromFlag == "" || *toFlag == "" {
+			usageFatalf("Give --from and --to; try %s", dateExamples)
+		}
+		now := time.Now()
+		start, err := parseDate(*fromFlag, now)
+		if err != nil {
+			usageFatalf("Invalid --from: %v", err)
+		}
+		end, err := parseDate(*toFlag, now)
+		if err != nil {
+			usageFatalf("Invalid --to: %v", err)
+		}
+		block := events.Block{Title: oooTitle, Start: start, End: end, TimeZone: *tzFlag, Decline: oooDecline, Message: oooMessage}
+		item, err := block.OutOfOffice()
+		if err != nil {
+			fatal("Invalid event", &UsageError{err})
+		}
+		insertBlock(item)
+	},
+}
+
+var focusCmd = &cobra.Command{
+	Use:   "focus [RANGE]",
+	Short: "Block focus time",
+	Long: "Create a focus time event for RANGE, such as 14:00-16:00, on --day. Chat is set to do not disturb unless " +
+		"--do-not-disturb=false, and meetings in that time are declined for you as --decline says: only invitations " +
+		"that arrive later (the default), all of them, or none.\n\n" +
+		"Focus time can only be created on a primary calendar.",
+	Example: `  go-gcal-cli focus --today 14:00-16:00
+  go-gcal-cli focus 9am-11am --day "next monday" --decline none`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		spec := focusToday
+		switch {
+		case len(args) > 0 && spec != "":
+			usageFatalf("Give RANGE or --today, not both")
+		case len(args) > 0:
+			spec = args[0]
+		case spec == "":
+			usageFatalf("Give the time to block, such as 14:00-16:00")
+		}
+		if cmd.Flags().Changed("today") && cmd.Flags().Changed("day") {
+			usageFatalf("--today and --day cannot be combined")
+		}
+		day, err := parseDate(focusDay, time.Now())
+		if err != nil {
+			usageFatalf("Invalid --day: %v", err)
+		}
+		start, end, err := parseTimeRange(spec, day)
+		if err != nil {
+			usageFatalf("Invalid range: %v", err)
+		}
+		block := events.Block{Title: focusTitle, Start: start, End: end, TimeZone: *tzFlag, Decline: focusDecline, Message: focusMessage}
+		item, err := block.FocusTime(focusDND)
+		if err != nil {
+			fatal("Invalid event", &UsageError{err})
+		}
+		insertBlock(item)
+	},
+}
+
+func init() {
+	declineHelp := "meetings to decline for you: all, new or none"
+	f := oooCmd.Flags()
+	f.StringVar(&oooTitle, "title", "Out of office", "event title")
+	f.StringVar(&oooDecline, "decline", "all", declineHelp)
+	f.StringVar(&oooMessage, "message", "", "reason sent with the declines")
+	f = focusCmd.Flags()
+	f.StringVar(&focusTitle, "title", "Focus time", "event title")
+	f.StringVar(&focusDecline, "decline", "new", declineHelp)
+	f.StringVar(&focusMessage, "message", "", "reason sent with the declines")
+	f.StringVar(&focusDay, "day", "today", "day to block the time on")
+	f.StringVar(&focusToday, "today", "", "time to block today, instead of RANGE")
+	f.BoolVar(&focusDND, "do-not-disturb", true, "set chat to do not disturb during the focus time")
+	rootCmd.AddCommand(oooCmd, focusCmd)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The open command's flags.
+var (
+	openWithin time.Duration
+	openPick   bool
+)
+
+// joinableMeetings returns the meetings with a link that are on at now or
+// start within within, the one starting closest to now first: at 13:58 the
+// 14:00 meeting beats the one that started at 13:00.
+func joinableMeetings(items []*calendar.Event, now time.Time, within time.Duration) []*calendar.Event {
+	var meetings []*calendar.Event
+	for _, item := range items {
+		startTime, endTime, ok := eventTimes(item)
+		if !ok || isRecurringMaster(item) || declinedBySelf(item) || eventLink(item, *linkSource) == "" {
+			continue
+		}
+		if endTime.After(now) && startTime.Sub(now) <= within {
+			meetings = append(meetings, item)
+		}
+	}
+	distance := func(item *calendar.Event) time.Duration {
+		return eventStart(item).Sub(now).Abs()
+	}
+	sort.SliceStable(meetings, func(i, j int) bool {
+		return distance(meetings[i]) < distance(meetings[j])
+	})
+	return meetings
+}
+
+// runOpen opens the link of the meeting to join now, asking which one with
+// --pick when there are several. It reports false when there is none.
+func runOpen(ctx context.Context, srv *calendar.Service, now time.Time) (bool, error) {
+	events, err := fetchEvents(ctx, srv, now, now.Add(openWithin))
+	if err != nil {
+		return false, err
+	}
+	warnStale(os.Stderr)
+	meetings := joinableMeetings(events.Items, now, openWithin)
+	if len(meetings) == 0 {
+		return false, nil
+	}
+	item := meetings[0]
+	if openPick && len(meetings) > 1 {
+		if item, err = pickEvent("Several meetings are on:", meetings); err != nil {
+			return false, err
+		}
+	}
+	link := eventLink(item, *linkSource)
+	fmt.Printf("Joining %s: %s\n", item.Summary, link)
+	return true, openBrowser(link)
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open",
+	Short: "Join the meeting that is on now or about to start",
+	Long: "Open the link of the meeting in progress, or starting within --within, in the browser. When several " +
+		"qualify the one starting closest to now wins; --pick asks instead.\n\n" +
+		"Declined invitations and events without a link are skipped. The exit status is 1 if there is nothing to join.",
+	Example: `  go-gcal-cli open
+  go-gcal-cli open --pick --within 30m`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		found, err := runOpen(ctx, srv, time.Now())
+		if err != nil {
+			fatal("Unable to join the meeting", err)
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "No meeting with a link is on or starts within %s.\n", shortDuration(openWithin))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	openCmd.Flags().DurationVar(&openWithin, "within", 10*time.Minute, "also join meetings starting this soon")
+	openCmd.Flags().BoolVar(&openPick, "pick", false, "ask which meeting to join when several are on")
+	rootCmd.AddCommand(openCmd)
+}
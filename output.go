@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Event is the structured form of an event written by --output json, csv
+// and tsv, and the data --format templates are executed on.
+type Event struct {
+	ID        string     `json:"id"`
+	Calendar  string     `json:"calendar_id"`
+	Summary   string     `json:"summary"`
+	Start     time.Time  `json:"start"`
+	End       time.Time  `json:"end"`
+	AllDay    bool       `json:"all_day"`
+	TimeZone  string     `json:"time_zone,omitempty"`
+	Location  string     `json:"location,omitempty"`
+	Link      string     `json:"link,omitempty"`
+	Organizer string     `json:"organizer,omitempty"`
+	Attendees []Attendee `json:"attendees,omitempty"`
+	Color     string     `json:"color,omitempty"`
+}
+
+// Attendee is one guest of an Event.
+type Attendee struct {
+	Email    string `json:"email"`
+	Name     string `json:"name,omitempty"`
+	Response string `json:"response"`
+	Optional bool   `json:"optional,omitempty"`
+	Self     bool   `json:"self,omitempty"`
+}
+
+// newEvent converts an API event. All-day events run from local midnight of
+// their start date to local midnight after the last day.
+func newEvent(item *calendar.Event) Event {
+	e := Event{
+		ID:       item.Id,
+		Calendar: eventCalendar(item),
+		Summary:  item.Summary,
+		Location: item.Location,
+		Link:     eventLink(item, *linkSource),
+		Color:    strings.ToLower(eventColorName(item)),
+	}
+	if loc := eventZone(item); loc != nil {
+		e.TimeZone = loc.String()
+	}
+	if startTime, endTime, ok := eventTimes(item); ok {
+		e.Start, e.End = startTime, endTime
+	} else {
+		e.AllDay = true
+		e.Start = eventStart(item)
+		if item.End != nil {
+			e.End, _ = time.ParseInLocation("2006-01-02", item.End.Date, time.Local)
+		}
+	}
+	if item.Organizer != nil {
+		e.Organizer = item.Organizer.Email
+	}
+	for _, a := range item.Attendees {
+		e.Attendees = append(e.Attendees, Attendee{
+			Email:    a.Email,
+			Name:     a.DisplayName,
+			Response: a.ResponseStatus,
+			Optional: a.Optional,
+			Self:     a.Self,
+		})
+	}
+	return e
+}
+
+// writeJSON writes items as an indented JSON array, one object per event.
+func writeJSON(w io.Writer, items []*calendar.Event) error {
+	events := make([]Event, 0, len(items))
+	for _, item := range items {
+		events = append(events, newEvent(item))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(events)
+}
+
+// formatTime renders a start or end for delimited output: RFC3339, or just
+// the date for all-day events.
+func (e Event) formatTime(t time.Time) string {
+	if e.AllDay {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// eventWhen describes when an event starts for confirmation messages, e.g.
+// "Mon 2024-06-03 14:00", or just the date for all-day events.
+func eventWhen(item *calendar.Event) string {
+	e := newEvent(item)
+	if e.AllDay {
+		return e.Start.Format("Mon 2006-01-02")
+	}
+	return e.Start.Local().Format("Mon 2006-01-02 15:04")
+}
+
+// eventColumns are the fields --columns can pick from.
+var eventColumns = map[string]func(e Event) string{
+	"id":        func(e Event) string { return e.ID },
+	"calendar":  func(e Event) string { return e.Calendar },
+	"summary":   func(e Event) string { return e.Summary },
+	"start":     func(e Event) string { return e.formatTime(e.Start) },
+	"end":       func(e Event) string { return e.formatTime(e.End) },
+	"all_day":   func(e Event) string { return strconv.FormatBool(e.AllDay) },
+	"location":  func(e Event) string { return e.Location },
+	"link":      func(e Event) string { return e.Link },
+	"organizer": func(e Event) string { return e.Organizer },
+	"color":     func(e Event) string { return e.Color },
+	"attendees": func(e Event) string {
+		emails := make([]string, len(e.Attendees))
+		for i, a := range e.Attendees {
+			emails[i] = a.Email
+		}
+		return strings.Join(emails, ";")
+	},
+}
+
+// parseColumns splits a --columns value, rejecting unknown names.
+func parseColumns(spec string) ([]string, error) {
+	var columns []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := eventColumns[name]; !ok {
+			var names []string
+			for n := range eventColumns {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown column %q; use %s", name, strings.Join(names, ", "))
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
+
+// writeDelimited writes items as CSV, or as TSV when comma is a tab. TSV
+// fields are never quoted; tabs and newlines in them become spaces so that
+// every event stays on one line for awk and cut.
+func writeDelimited(w io.Writer, items []*calendar.Event, comma rune, columns []string) error {
+	records := [][]string{}
+	if !*noHeader {
+		records = append(records, columns)
+	}
+	for _, item := range items {
+		e := newEvent(item)
+		record := make([]string, len(columns))
+		for i, name := range columns {
+			record[i] = eventColumns[name](e)
+		}
+		records = append(records, record)
+	}
+	return writeRecords(w, records, comma)
+}
+
+// writeRecords writes records as CSV, or as TSV when comma is a tab.
+func writeRecords(w io.Writer, records [][]string, comma rune) error {
+	if comma != '\t' {
+		cw := csv.NewWriter(w)
+		cw.Comma = comma
+		return cw.WriteAll(records)
+	}
+	clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ")
+	for _, record := range records {
+		for i := range record {
+			record[i] = clean.Replace(record[i])
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(record, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateFuncs are available to --format templates besides the builtins.
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"until": func(t time.Time) time.Duration {
+		return time.Until(t).Round(time.Minute)
+	},
+}
+
+// writeTemplate executes the --format template once per event, ending each
+// with a newline.
+func writeTemplate(w io.Writer, items []*calendar.Event, text string) error {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+	for _, item := range items {
+		if err := tmpl.Execute(w, newEvent(item)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputFormats are the values --output accepts. Commands fall back to a
+// table for those they do not support.
+var outputFormats = []string{"table", "json", "csv", "tsv", "png", "prometheus"}
+
+func validOutput(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q; use one of %s", format, strings.Join(outputFormats, ", "))
+}
+
+// writeEvents handles the structured output formats shared by list and
+// agenda, and --format which overrides them all. It reports false for
+// formats it does not handle.
+func writeEvents(w io.Writer, items []*calendar.Event) (bool, error) {
+	if *formatFlag != "" {
+		return true, writeTemplate(w, items, *formatFlag)
+	}
+	switch *outputFormat {
+	case "json":
+		return true, writeJSON(w, items)
+	case "csv", "tsv":
+		columns, err := parseColumns(*columnsFlag)
+		if err != nil {
+			return true, fmt.Errorf("--columns: %w", err)
+		}
+		comma := ','
+		if *outputFormat == "tsv" {
+			comma = '\t'
+		}
+		return true, writeDelimited(w, items, comma, columns)
+	}
+	return false, nil
+}
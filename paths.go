@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	credentialsFile = "go-gcal-cli-credentials.json"
+	tokenFile       = "token.json"
+)
+
+// configFiles are the files that used to live in the working directory.
+var configFiles = []string{credentialsFile, tokenFile}
+
+const appName = "go-gcal-cli"
+
+// configDir returns the directory holding the OAuth client credentials:
+// --config-dir, else $XDG_CONFIG_HOME/go-gcal-cli, else the platform's
+// user config directory.
+func configDir() (string, error) {
+	if *configDirFlag != "" {
+		return *configDirFlag, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// cacheDir returns the directory for tokens and other state the tool can
+// recreate: --config-dir, else $XDG_CACHE_HOME/go-gcal-cli, else the
+// platform's user cache directory.
+func cacheDir() (string, error) {
+	if *configDirFlag != "" {
+		return *configDirFlag, nil
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// fileDir returns the directory name belongs in. Only the credentials are
+// configuration; tokens are cache.
+func fileDir(name string) (string, error) {
+	if name == credentialsFile {
+		return configDir()
+	}
+	return cacheDir()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// configPath returns where name should be read from and written to. Files
+// still sitting in the working directory keep being used from there until
+// they are migrated.
+func configPath(name string) string {
+	dir, err := fileDir(name)
+	if err != nil {
+		return name
+	}
+	path := filepath.Join(dir, name)
+	if !fileExists(path) && fileExists(name) {
+		return name
+	}
+	return path
+}
+
+// migrateConfig looks for files in the working directory that have no
+// counterpart in their new directory. With move set they are moved there,
+// otherwise the user is told how to do so. Originals are never touched
+// without move.
+func migrateConfig(w io.Writer, move bool) error {
+	for _, name := range configFiles {
+		dir, err := fileDir(name)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, name)
+		if !fileExists(name) || fileExists(target) {
+			continue
+		}
+		if !move {
+			fmt.Fprintf(w, "Found %s in the current directory; run with --migrate to move it to %s\n", name, dir)
+			continue
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		if err := moveFile(name, target); err != nil {
+			return fmt.Errorf("moving %s: %w", name, err)
+		}
+		fmt.Fprintf(w, "Moved %s to %s\n", name, target)
+	}
+	return nil
+}
+
+// moveFile renames src to dst, copying when they are on different devices.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	cellWidth  = 7
+	cellHeight = 13
+	imgPadding = 8
+)
+
+var (
+	defaultFg = color.RGBA{0xc0, 0xc0, 0xc0, 0xff}
+	defaultBg = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// ansi16 is the standard xterm palette for the first 16 colors.
+var ansi16 = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+type cell struct {
+	r      rune
+	fg, bg color.RGBA
+}
+
+// renderImage re-renders a view with true color enabled so that the styles
+// survive even when stdout is not a terminal.
+func renderImage(render func() string) string {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+	return render()
+}
+
+// renderPNG draws an ANSI-styled string onto a character grid and writes it
+// to path as a PNG.
+func renderPNG(ansi string, path string) error {
+	grid := parseANSI(ansi)
+
+	cols := 0
+	for _, line := range grid {
+		if len(line) > cols {
+			cols = len(line)
+		}
+	}
+	width := cols*cellWidth + 2*imgPadding
+	height := len(grid)*cellHeight + 2*imgPadding
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{defaultBg}, image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	for y, line := range grid {
+		for x, c := range line {
+			x0 := imgPadding + x*cellWidth
+			y0 := imgPadding + y*cellHeight
+			rect := image.Rect(x0, y0, x0+cellWidth, y0+cellHeight)
+			draw.Draw(img, rect, &image.Uniform{c.bg}, image.Point{}, draw.Src)
+			if drawBox(img, rect, c.r, c.fg) {
+				continue
+			}
+			d := font.Drawer{
+				Dst:  img,
+				Src:  &image.Uniform{c.fg},
+				Face: face,
+				Dot:  fixed.P(x0, y0+face.Ascent),
+			}
+			d.DrawString(string(c.r))
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// drawBox renders box-drawing runes as lines, since the bitmap font only
+// covers ASCII. It reports whether r was handled.
+func drawBox(img *image.RGBA, rect image.Rectangle, r rune, fg color.RGBA) bool {
+	var up, down, left, right bool
+	switch r {
+	case '─':
+		left, right = true, true
+	case '│':
+		up, down = true, true
+	case '┌':
+		down, right = true, true
+	case '┐':
+		down, left = true, true
+	case '└':
+		up, right = true, true
+	case '┘':
+		up, left = true, true
+	case '├':
+		up, down, right = true, true, true
+	case '┤':
+		up, down, left = true, true, true
+	case '┬':
+		left, right, down = true, true, true
+	case '┴':
+		left, right, up = true, true, true
+	case '┼':
+		up, down, left, right = true, true, true, true
+	default:
+		return false
+	}
+
+	cx := rect.Min.X + rect.Dx()/2
+	cy := rect.Min.Y + rect.Dy()/2
+	src := &image.Uniform{fg}
+	if left {
+		draw.Draw(img, image.Rect(rect.Min.X, cy, cx+1, cy+1), src, image.Point{}, draw.Src)
+	}
+	if right {
+		draw.Draw(img, image.Rect(cx, cy, rect.Max.X, cy+1), src, image.Point{}, draw.Src)
+	}
+	if up {
+		draw.Draw(img, image.Rect(cx, rect.Min.Y, cx+1, cy+1), src, image.Point{}, draw.Src)
+	}
+	if down {
+		draw.Draw(img, image.Rect(cx, cy, cx+1, rect.Max.Y), src, image.Point{}, draw.Src)
+	}
+	return true
+}
+
+// parseANSI splits s into lines of styled cells, interpreting SGR color
+// sequences and discarding everything else.
+func parseANSI(s string) [][]cell {
+	var grid [][]cell
+	var line []cell
+	fg, bg := defaultFg, defaultBg
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\x1b' && i+1 < len(runes) && runes[i+1] == '[':
+			j := i + 2
+			for j < len(runes) && (runes[j] < '@' || runes[j] > '~') {
+				j++
+			}
+			if j < len(runes) && runes[j] == 'm' {
+				fg, bg = applySGR(string(runes[i+2:j]), fg, bg)
+			}
+			i = j
+		case r == '\n':
+			grid = append(grid, line)
+			line = nil
+		case r == '\r':
+		default:
+			line = append(line, cell{r: r, fg: fg, bg: bg})
+		}
+	}
+	if len(line) > 0 {
+		grid = append(grid, line)
+	}
+	return grid
+}
+
+func applySGR(params string, fg, bg color.RGBA) (color.RGBA, color.RGBA) {
+	if params == "" {
+		return defaultFg, defaultBg
+	}
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		n, _ := strconv.Atoi(codes[i])
+		switch {
+		case n == 0:
+			fg, bg = defaultFg, defaultBg
+		case n == 39:
+			fg = defaultFg
+		case n == 49:
+			bg = defaultBg
+		case n >= 30 && n <= 37:
+			fg = ansi16[n-30]
+		case n >= 40 && n <= 47:
+			bg = ansi16[n-40]
+		case n >= 90 && n <= 97:
+			fg = ansi16[n-90+8]
+		case n >= 100 && n <= 107:
+			bg = ansi16[n-100+8]
+		case n == 38 || n == 48:
+			var c color.RGBA
+			var ok bool
+			c, i, ok = extendedColor(codes, i)
+			if !ok {
+				continue
+			}
+			if n == 38 {
+				fg = c
+			} else {
+				bg = c
+			}
+		}
+	}
+	return fg, bg
+}
+
+// extendedColor decodes a 256-color (5;n) or true color (2;r;g;b) sequence
+// starting at codes[i], returning the color and the index of its last part.
+func extendedColor(codes []string, i int) (color.RGBA, int, bool) {
+	if i+1 >= len(codes) {
+		return color.RGBA{}, i, false
+	}
+	switch codes[i+1] {
+	case "5":
+		if i+2 >= len(codes) {
+			return color.RGBA{}, i, false
+		}
+		n, _ := strconv.Atoi(codes[i+2])
+		return xterm256(n), i + 2, true
+	case "2":
+		if i+4 >= len(codes) {
+			return color.RGBA{}, i, false
+		}
+		r, _ := strconv.Atoi(codes[i+2])
+		g, _ := strconv.Atoi(codes[i+3])
+		b, _ := strconv.Atoi(codes[i+4])
+		return color.RGBA{uint8(r), uint8(g), uint8(b), 0xff}, i + 4, true
+	}
+	return color.RGBA{}, i, false
+}
+
+func xterm256(n int) color.RGBA {
+	switch {
+	case n < 16:
+		return ansi16[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return color.RGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 0xff}
+	default:
+		v := uint8(8 + (n-232)*10)
+		return color.RGBA{v, v, v, 0xff}
+	}
+}
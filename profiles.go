@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+const (
+	profilesFile   = "profiles.json"
+	profileEnv     = "GCAL_PROFILE"
+	defaultProfile = "default"
+)
+
+// profile is one named Google account. Each profile has its own token.
+type profile struct {
+	Calendar string `json:"calendar,omitempty"`
+}
+
+// profileSet is the on-disk list of profiles.
+type profileSet struct {
+	Profiles map[string]profile `json:"profiles"`
+}
+
+// activeProfile returns the profile selected with --profile or
+// $GCAL_PROFILE.
+func activeProfile() string {
+	if *profileFlag != "" {
+		return *profileFlag
+	}
+	if name := os.Getenv(profileEnv); name != "" {
+		return name
+	}
+	return defaultProfile
+}
+
+func profilesPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profilesFile), nil
+}
+
+func loadProfiles() (*profileSet, error) {
+	set := &profileSet{Profiles: map[string]profile{}}
+	path, err := profilesPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return set, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, set); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if set.Profiles == nil {
+		set.Profiles = map[string]profile{}
+	}
+	return set, nil
+}
+
+func saveProfiles(set *profileSet) error {
+	path, err := profilesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// names returns the profile names in order, always including the default.
+func (set *profileSet) names() []string {
+	names := []string{defaultProfile}
+	for name := range set.Profiles {
+		if name != defaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// currentProfile looks up the active profile. The default profile always
+// exists; others must have been added first.
+func currentProfile() (string, profile, error) {
+	name := activeProfile()
+	set, err := loadProfiles()
+	if err != nil {
+		return name, profile{}, err
+	}
+	p, ok := set.Profiles[name]
+	if !ok && name != defaultProfile {
+		return name, p, fmt.Errorf("unknown profile %q; create it with \"profile add %s\"", name, name)
+	}
+	return name, p, nil
+}
+
+// profileNamePattern is what profile names may look like. They become part
+// of the token, cache and socket file names.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+func validProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("%q may only contain lowercase letters, digits, _ and -", name)
+	}
+	return nil
+}
+
+// profileFileName suffixes a per-profile file name with the profile, leaving
+// the default profile's names unchanged for existing installs.
+func profileFileName(base, ext, name string) string {
+	if name == defaultProfile {
+		return base + ext
+	}
+	return base + "-" + name + ext
+}
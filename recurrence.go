@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rruleDays names the two-letter weekdays of RRULE BYDAY values.
+var rruleDays = map[string]string{
+	"MO": "Mon", "TU": "Tue", "WE": "Wed", "TH": "Thu", "FR": "Fri", "SA": "Sat", "SU": "Sun",
+}
+
+// rruleUnits names the periods of RRULE FREQ values.
+var rruleUnits = map[string]string{
+	"DAILY": "day", "WEEKLY": "week", "MONTHLY": "month", "YEARLY": "year",
+}
+
+// ordinal spells n as "1st", "2nd", "-1" as "last" and so on.
+func ordinal(n int) string {
+	switch {
+	case n == -1:
+		return "last"
+	case n < 0:
+		return ordinal(-n) + " to last"
+	case n%100 >= 11 && n%100 <= 13:
+		return strconv.Itoa(n) + "th"
+	case n%10 == 1:
+		return strconv.Itoa(n) + "st"
+	case n%10 == 2:
+		return strconv.Itoa(n) + "nd"
+	case n%10 == 3:
+		return strconv.Itoa(n) + "rd"
+	}
+	return strconv.Itoa(n) + "th"
+}
+
+// describeByDay spells a BYDAY entry such as "MO" or "-1FR" ("last Fri").
+func describeByDay(day string) string {
+	i := strings.IndexAny(day, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	if i < 0 {
+		return day
+	}
+	name, ok := rruleDays[day[i:]]
+	if !ok {
+		name = day[i:]
+	}
+	if n, err := strconv.Atoi(day[:i]); err == nil && i > 0 {
+		return "the " + ordinal(n) + " " + name
+	}
+	return name
+}
+
+// describeRRule spells an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"
+// as "every 2 weeks on Mon, Wed". Parts it does not know are left out.
+func describeRRule(rule string) string {
+	parts := map[string]string{}
+	for _, part := range strings.Split(rule, ";") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			parts[strings.ToUpper(key)] = value
+		}
+	}
+	unit, ok := rruleUnits[parts["FREQ"]]
+	if !ok {
+		return rule
+	}
+	desc := "every " + unit
+	if n, err := strconv.Atoi(parts["INTERVAL"]); err == nil && n > 1 {
+		desc = fmt.Sprintf("every %d %ss", n, unit)
+	}
+	if byDay := parts["BYDAY"]; byDay != "" {
+		var days []string
+		for _, day := range strings.Split(byDay, ",") {
+			days = append(days, describeByDay(day))
+		}
+		if byDay == "MO,TU,WE,TH,FR" && desc == "every week" {
+			desc = "every weekday"
+		} else {
+			desc += " on " + strings.Join(days, ", ")
+		}
+	}
+	if byMonthDay := parts["BYMONTHDAY"]; byMonthDay != "" {
+		var days []string
+		for _, day := range strings.Split(byMonthDay, ",") {
+			if n, err := strconv.Atoi(day); err == nil {
+				days = append(days, ordinal(n))
+			}
+		}
+		desc += " on the " + strings.Join(days, ", ")
+	}
+	if byMonth := parts["BYMONTH"]; byMonth != "" {
+		var months []string
+		for _, month := range strings.Split(byMonth, ",") {
+			if n, err := strconv.Atoi(month); err == nil && n >= 1 && n <= 12 {
+				months = append(months, time.Month(n).String()[:3])
+			}
+		}
+		desc += " in " + strings.Join(months, ", ")
+	}
+	switch until := parts["UNTIL"]; {
+	case parts["COUNT"] == "1":
+		desc += ", once"
+	case parts["COUNT"] != "":
+		desc += ", " + parts["COUNT"] + " times"
+	case until != "":
+		// UNTIL is a date or a UTC date-time, whose date is all that matters
+		// here.
+		if t, err := time.Parse("20060102", until[:min(8, len(until))]); err == nil {
+			desc += " until " + t.Format("2006-01-02")
+		}
+	}
+	return desc
+}
+
+// describeRecurrence spells an event's recurrence lines in words, e.g.
+// "Every week on Mon, Wed until 2024-12-31, 2 skipped".
+func describeRecurrence(lines []string) string {
+	var rules []string
+	exceptions, extras := 0, 0
+	for _, line := range lines {
+		name, value, _ := strings.Cut(line, ":")
+		// Properties may carry parameters, as in "EXDATE;TZID=...".
+		name, _, _ = strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "RRULE":
+			rules = append(rules, describeRRule(value))
+		case "EXDATE":
+			exceptions += len(strings.Split(value, ","))
+		case "RDATE":
+			extras += len(strings.Split(value, ","))
+		}
+	}
+	desc := strings.Join(rules, "; ")
+	if exceptions > 0 {
+		desc += fmt.Sprintf(", %d skipped", exceptions)
+	}
+	if extras > 0 {
+		desc += fmt.Sprintf(", %d added", extras)
+	}
+	desc = strings.TrimPrefix(desc, ", ")
+	if desc == "" {
+		return ""
+	}
+	return strings.ToUpper(desc[:1]) + desc[1:]
+}
+
+// repeatFreqs maps the periods --repeat accepts to RRULE FREQ values.
+var repeatFreqs = map[string]string{
+	"day": "DAILY", "days": "DAILY", "daily": "DAILY",
+	"week": "WEEKLY", "weeks": "WEEKLY", "weekly": "WEEKLY",
+	"month": "MONTHLY", "months": "MONTHLY", "monthly": "MONTHLY",
+	"year": "YEARLY", "years": "YEARLY", "yearly": "YEARLY", "annually": "YEARLY",
+}
+
+// repeatPattern splits a --repeat value such as "every 2 weeks on mon,wed
+// until 2024-12-31" or "monthly on the last fri, 6 times" into its period,
+// days, end date and count.
+var repeatPattern = regexp.MustCompile(`^(?:every\s+(\d+)\s+)?(?:every\s+)?([a-z]+)` +
+	`(?:\s+on\s+(?:the\s+)?(.+?))?` +
+	`(?:,?\s+until\s+(.+?))?` +
+	`(?:,?\s+(?:for\s+)?(\d+)\s+times)?$`)
+
+// rawFreqPattern checks the FREQ of a raw RRULE.
+var rawFreqPattern = regexp.MustCompile(`(^|;)FREQ=(SECONDLY|MINUTELY|HOURLY|DAILY|WEEKLY|MONTHLY|YEARLY)(;|$)`)
+
+// ordinalPattern matches "2nd", "last" or "-1" before a weekday, or on its
+// own as a day of the month.
+var ordinalPattern = regexp.MustCompile(`^(last|-?\d+)(?:st|nd|rd|th)?$`)
+
+// parseRepeat turns a --repeat value into an RRULE line. Raw rules, with or
+// without the "RRULE:" prefix, are passed through; otherwise the value is
+// read as a phrase such as "weekdays", "every 2 weeks on fri", "monthly on
+// the 15th", "monthly on the last fri" or "weekly on mon,wed until
+// 2024-12-31". UNTIL is the end of that day, in UTC unless allDay.
+func parseRepeat(value string, now time.Time, allDay bool) (string, error) {
+	value = strings.TrimSpace(value)
+	if rule := strings.ToUpper(strings.TrimPrefix(value, "RRULE:")); strings.Contains(rule, "FREQ=") {
+		if !rawFreqPattern.MatchString(rule) {
+			return "", fmt.Errorf("unknown FREQ in %q", value)
+		}
+		return "RRULE:" + rule, nil
+	}
+	s := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	m := repeatPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("unrecognized repeat %q; try daily, weekdays, every 2 weeks on fri, monthly on the last fri or an RRULE", value)
+	}
+	interval, period, on, until, count := m[1], m[2], m[3], m[4], m[5]
+	parts := []string{}
+	switch period {
+	case "weekday", "weekdays":
+		if on != "" {
+			return "", fmt.Errorf("weekdays cannot be given days")
+		}
+		parts = append(parts, "FREQ=WEEKLY", "BYDAY=MO,TU,WE,TH,FR")
+	default:
+		freq, ok := repeatFreqs[period]
+		if !ok {
+			return "", fmt.Errorf("unknown period %q; use day, week, month or year", period)
+		}
+		parts = append(parts, "FREQ="+freq)
+	}
+	if n, _ := strconv.Atoi(interval); n > 1 {
+		parts = append(parts, "INTERVAL="+interval)
+	}
+	if on != "" {
+		byDay, byMonthDay, err := repeatDays(on)
+		if err != nil {
+			return "", err
+		}
+		if byDay != "" {
+			parts = append(parts, "BYDAY="+byDay)
+		}
+		if byMonthDay != "" {
+			parts = append(parts, "BYMONTHDAY="+byMonthDay)
+		}
+	}
+	if until != "" {
+		t, err := parseDate(until, now)
+		if err != nil {
+			return "", fmt.Errorf("until: %w", err)
+		}
+		if allDay {
+			parts = append(parts, "UNTIL="+t.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+startOfDay(t).AddDate(0, 0, 1).Add(-time.Second).UTC().Format("20060102T150405Z"))
+		}
+	}
+	if count != "" {
+		if until != "" {
+			return "", fmt.Errorf("give until or a number of times, not both")
+		}
+		parts = append(parts, "COUNT="+count)
+	}
+	return "RRULE:" + strings.Join(parts, ";"), nil
+}
+
+// repeatDays reads the days after "on": weekdays such as "mon,wed" or "the
+// last fri" become BYDAY, days of the month such as "the 15th" BYMONTHDAY.
+func repeatDays(on string) (string, string, error) {
+	var byDay, byMonthDay []string
+	// pending is an ordinal that qualifies the weekday after it, as in "the
+	// 2nd tue", or else is a day of the month.
+	pending := ""
+	for _, field := range strings.FieldsFunc(on, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if field == "and" || field == "the" {
+			continue
+		}
+		if m := ordinalPattern.FindStringSubmatch(field); m != nil {
+			if pending != "" {
+				byMonthDay = append(byMonthDay, pending)
+			}
+			pending = m[1]
+			if pending == "last" {
+				pending = "-1"
+			}
+			continue
+		}
+		wd, ok := parseWeekday(field)
+		if !ok {
+			return "", "", fmt.Errorf("unknown day %q", field)
+		}
+		byDay = append(byDay, pending+strings.ToUpper(wd.String()[:2]))
+		pending = ""
+	}
+	if pending != "" {
+		byMonthDay = append(byMonthDay, pending)
+	}
+	return strings.Join(byDay, ","), strings.Join(byMonthDay, ","), nil
+}
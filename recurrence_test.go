@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRepeat(t *testing.T) {
+	now := time.Date(2024, 6, 5, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		allDay bool
+		want   string
+	}{
+		{"daily", false, "RRULE:FREQ=DAILY"},
+		{"  Every  Week ", false, "RRULE:FREQ=WEEKLY"},
+		{"weekdays", false, "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{"every 2 weeks on fri", false, "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"},
+		{"every 1 day", false, "RRULE:FREQ=DAILY"},
+		{"weekly on mon,wed", false, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"weekly on mon, wed and friday", false, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+		{"monthly on the 15th", false, "RRULE:FREQ=MONTHLY;BYMONTHDAY=15"},
+		{"monthly on the 1st and 15th", false, "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,15"},
+		{"monthly on the last fri", false, "RRULE:FREQ=MONTHLY;BYDAY=-1FR"},
+		{"monthly on the 2nd tue", false, "RRULE:FREQ=MONTHLY;BYDAY=2TU"},
+		{"monthly on the last", false, "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"every 3 months on the last fri, 6 times", false, "RRULE:FREQ=MONTHLY;INTERVAL=3;BYDAY=-1FR;COUNT=6"},
+		{"yearly for 5 times", false, "RRULE:FREQ=YEARLY;COUNT=5"},
+		{"weekly on mon,wed until 2024-12-31", false, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20241231T235959Z"},
+		{"daily until 2024-12-31", true, "RRULE:FREQ=DAILY;UNTIL=20241231"},
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO", false, "RRULE:FREQ=WEEKLY;BYDAY=MO"},
+		{"freq=monthly;bymonthday=1", false, "RRULE:FREQ=MONTHLY;BYMONTHDAY=1"},
+	}
+	for _, tt := range tests {
+		got, err := parseRepeat(tt.value, now, tt.allDay)
+		if err != nil {
+			t.Errorf("parseRepeat(%q): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRepeat(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseRepeatErrors(t *testing.T) {
+	now := time.Date(2024, 6, 5, 10, 30, 0, 0, time.UTC)
+	for _, value := range []string{
+		"",
+		"fortnightly",
+		"weekdays on mon",
+		"weekly on someday",
+		"weekly until never",
+		"daily until 2024-12-31, 3 times",
+		"RRULE:FREQ=SOMETIMES",
+	} {
+		if got, err := parseRepeat(value, now, false); err == nil {
+			t.Errorf("parseRepeat(%q) = %q, want an error", value, got)
+		}
+	}
+}
+
+func TestDescribeRRule(t *testing.T) {
+	tests := []struct {
+		rule, want string
+	}{
+		{"FREQ=DAILY", "every day"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", "every 2 weeks on Mon, Wed"},
+		{"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "every weekday"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TU,WE,TH,FR", "every 2 weeks on Mon, Tue, Wed, Thu, Fri"},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "every month on the last Fri"},
+		{"FREQ=MONTHLY;BYDAY=2TU", "every month on the 2nd Tue"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-2", "every month on the 1st, 2nd to last"},
+		{"FREQ=YEARLY;BYMONTH=3,11", "every year in Mar, Nov"},
+		{"FREQ=WEEKLY;COUNT=1", "every week, once"},
+		{"FREQ=WEEKLY;COUNT=6", "every week, 6 times"},
+		{"FREQ=DAILY;UNTIL=20241231T235959Z", "every day until 2024-12-31"},
+		{"FREQ=DAILY;UNTIL=20241231", "every day until 2024-12-31"},
+		{"FREQ=HOURLY", "FREQ=HOURLY"},
+	}
+	for _, tt := range tests {
+		if got := describeRRule(tt.rule); got != tt.want {
+			t.Errorf("describeRRule(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeRecurrence(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20241231"}, "Every week on Mon, Wed until 2024-12-31"},
+		{[]string{"RRULE:FREQ=DAILY", "EXDATE;TZID=Europe/Berlin:20240610T090000,20240611T090000"}, "Every day, 2 skipped"},
+		{[]string{"RRULE:FREQ=DAILY", "RDATE:20240615T090000Z"}, "Every day, 1 added"},
+		{[]string{"RDATE:20240615T090000Z"}, "1 added"},
+		{[]string{"RRULE:FREQ=DAILY;COUNT=3", "rrule:FREQ=YEARLY"}, "Every day, 3 times; every year"},
+	}
+	for _, tt := range tests {
+		if got := describeRecurrence(tt.lines); got != tt.want {
+			t.Errorf("describeRecurrence(%q) = %q, want %q", tt.lines, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The API's limits on reminder overrides.
+const (
+	maxReminders       = 5
+	maxReminderMinutes = 4 * 7 * 24 * 60
+)
+
+// The reminders command's flags.
+var (
+	reminderSearch  string
+	reminderPopups  stringList
+	reminderEmails  stringList
+	reminderDefault bool
+	reminderNone    bool
+	reminderScope   string
+)
+
+// reminderDays matches reminder times in days or weeks, which
+// time.ParseDuration does not take.
+var reminderDays = regexp.MustCompile(`^(\d+)(d|w)$`)
+
+// parseReminder turns how long before an event to remind, such as "10m",
+// "1h30m", "1d" or "2w", into whole minutes.
+func parseReminder(value string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	d, err := time.ParseDuration(s)
+	if m := reminderDays.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if d = time.Duration(n) * 24 * time.Hour; m[2] == "w" {
+			d *= 7
+		}
+	} else if err != nil {
+		return 0, fmt.Errorf("%q must be a time such as 10m, 1h or 1d", value)
+	}
+	if d < 0 || d%time.Minute != 0 || d/time.Minute > maxReminderMinutes {
+		return 0, fmt.Errorf("%q must be whole minutes up to 4 weeks", value)
+	}
+	return int64(d / time.Minute), nil
+}
+
+// reminderText writes minutes in the largest unit that divides them.
+func reminderText(minutes int64) string {
+	switch {
+	case minutes == 0:
+		return "0m"
+	case minutes%(7*24*60) == 0:
+		return fmt.Sprintf("%dw", minutes/(7*24*60))
+	case minutes%(24*60) == 0:
+		return fmt.Sprintf("%dd", minutes/(24*60))
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// reminderList describes reminders as "popup 10m before, email 1d before".
+func reminderList(reminders []*calendar.EventReminder) string {
+	if len(reminders) == 0 {
+		return "none"
+	}
+	var parts []string
+	for _, r := range reminders {
+		parts = append(parts, r.Method+" "+reminderText(r.Minutes)+" before")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reminderOverrides collects --popup and --email.
+func reminderOverrides() ([]*calendar.EventReminder, error) {
+	var overrides []*calendar.EventReminder
+	for _, flag := range []struct {
+		method string
+		values []string
+	}{{"popup", reminderPopups}, {"email", reminderEmails}} {
+		for _, v := range flag.values {
+			minutes, err := parseReminder(v)
+			if err != nil {
+				return nil, fmt.Errorf("--%s: %w", flag.method, err)
+			}
+			overrides = append(overrides, &calendar.EventReminder{Method: flag.method, Minutes: minutes, ForceSendFields: []string{"Minutes"}})
+		}
+	}
+	if len(overrides) > maxReminders {
+		return nil, fmt.Errorf("at most %d reminders are allowed", maxReminders)
+	}
+	return overrides, nil
+}
+
+// eventReminders describes item's reminders, which without overrides are
+// its calendar's defaults.
+func eventReminders(item *calendar.Event, calendarID string) string {
+	if item.Reminders == nil || item.Reminders.UseDefault {
+		text := "calendar default"
+		if entry := calendarEntry(calendarID); entry != nil {
+			text += " (" + reminderList(entry.DefaultReminders) + ")"
+		}
+		return text
+	}
+	return reminderList(item.Reminders.Overrides)
+}
+
+var remindersCmd = &cobra.Command{
+	Use:   "reminders [EVENT]",
+	Short: "Show or change an event's reminders",
+	Long: "Show the reminders of an event, given by ID or with --search by title, or replace them with the " +
+		"given --popup and --email ones. --default goes back to the calendar's defaults and --none silences " +
+		"the event.\n\n" +
+		"For an occurrence of a recurring event --scope picks what changes, as for edit. Reminders are your own, " +
+		"so guests are not told.",
+	Example: `  go-gcal-cli reminders abc123 --popup 10m --email 1d
+  go-gcal-cli reminders --search standup --none --scope all
+  go-gcal-cli reminders abc123 --default`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		if err := validScope(reminderScope); err != nil {
+			usageFatalf("Invalid --scope: %v", err)
+		}
+		overrides, err := reminderOverrides()
+		if err != nil {
+			usageFatalf("Invalid reminder: %v", err)
+		}
+		change := reminderDefault || reminderNone || len(overrides) > 0
+		if reminderDefault && (reminderNone || len(overrides) > 0) || reminderNone && len(overrides) > 0 {
+			usageFatalf("Use only one of --default, --none or --popup and --email")
+		}
+		if change {
+			requireScopes(writeScope)
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		calendarID, item, err := resolveEvent(ctx, srv, id, reminderSearch)
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		// The calendar's defaults, for events that use them.
+		if entries, _, err := cachedCalendars(ctx, srv, false); err == nil {
+			calendarList = entries
+		}
+		if !change {
+			fmt.Printf("%s  %s\nReminders: %s\n", eventWhen(item), item.Summary, eventReminders(item, calendarID))
+			return
+		}
+		reminders := &calendar.EventReminders{UseDefault: reminderDefault, Overrides: overrides, ForceSendFields: []string{"UseDefault", "Overrides"}}
+		patch := &calendar.Event{Reminders: reminders}
+		scope, master, err := seriesScope(ctx, srv, calendarID, item, reminderScope)
+		if err != nil {
+			fatal("Unable to find the series", err)
+		}
+		var updated *calendar.Event
+		switch {
+		case scope == events.ScopeFollowing:
+			updated, err = events.SplitSeries(ctx, srv, calendarID, master, item, patch, false, callOptions()...)
+		case master != nil:
+			updated, err = events.Patch(ctx, srv, calendarID, master.Id, patch, false, callOptions()...)
+		default:
+			updated, err = events.Patch(ctx, srv, calendarID, item.Id, patch, false, callOptions()...)
+		}
+		if err != nil {
+			fatal("Unable to update reminders", scopeError(err))
+		}
+		fmt.Printf("Updated %s  %s\nReminders: %s\n", eventWhen(updated), updated.Summary, eventReminders(updated, calendarID))
+	},
+}
+
+var remindersDefaultCmd = &cobra.Command{
+	Use:   "default",
+	Short: "Show or set the first --calendar's default reminders",
+	Long: "Show the reminders events on the first --calendar get unless they set their own, or replace them with " +
+		"the given --popup and --email ones. --none removes them.",
+	Example: `  go-gcal-cli reminders default --popup 10m
+  go-gcal-cli reminders default --calendar team --none`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		overrides, err := reminderOverrides()
+		if err != nil {
+			usageFatalf("Invalid reminder: %v", err)
+		}
+		if reminderNone && len(overrides) > 0 {
+			usageFatalf("Use either --none or --popup and --email")
+		}
+		change := reminderNone || len(overrides) > 0
+		if change {
+			// Calendar list entries are beyond the events scope.
+			requireScopes(calendar.CalendarScope)
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		id := calendarIDs()[0]
+		entry, err := srv.CalendarList.Get(id).Context(ctx).Do(callOptions()...)
+		if err != nil {
+			fatal("Unable to find calendar", err)
+		}
+		if change {
+			patch := &calendar.CalendarListEntry{DefaultReminders: overrides, ForceSendFields: []string{"DefaultReminders"}}
+			if entry, err = srv.CalendarList.Patch(id, patch).Context(ctx).Do(callOptions()...); err != nil {
+				fatal("Unable to update calendar", scopeError(err))
+			}
+		}
+		fmt.Printf("Default reminders of %s: %s\n", entryName(entry), reminderList(entry.DefaultReminders))
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{remindersCmd, remindersDefaultCmd} {
+		f := c.Flags()
+		f.Var(&reminderPopups, "popup", "notify this long before, e.g. 10m, 1h or 1d; repeatable")
+		f.Var(&reminderEmails, "email", "email this long before; repeatable")
+		f.BoolVar(&reminderNone, "none", false, "remove all reminders")
+	}
+	f := remindersCmd.Flags()
+	f.StringVar(&reminderSearch, "search", "", "find the event by title instead of ID")
+	f.BoolVar(&reminderDefault, "default", false, "use the calendar's default reminders")
+	f.StringVar(&reminderScope, "scope", events.ScopeThis, "what to change of a series: this, following or all occurrences")
+	remindersCmd.AddCommand(remindersDefaultCmd)
+	rootCmd.AddCommand(remindersCmd)
+}
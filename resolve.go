@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// stdin is shared by the interactive prompts so that buffered input is not
+// lost between them.
+var stdin = bufio.NewReader(os.Stdin)
+
+// resolveEvent finds the event a command acts on: by ID on the first
+// selected calendar, or with search set by title among the events from a
+// week ago to a month ahead. Several equally good matches are offered for
+// the user to pick from.
+func resolveEvent(ctx context.Context, srv *calendar.Service, id, search string) (string, *calendar.Event, error) {
+	if search == "" {
+		if id == "" {
+			return "", nil, errors.New("give an event ID or --search")
+		}
+		calendarID := calendarIDs()[0]
+		item, err := srv.Events.Get(calendarID, id).Context(ctx).Do(callOptions()...)
+		if err == nil {
+			rememberCalendar(item, calendarID)
+		}
+		return calendarID, item, err
+	}
+
+	if err := loadCalendars(ctx, srv); err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	events, err := fetchAll(ctx, calendarIDs(), func(ctx context.Context, id string) ([]*calendar.Event, error) {
+		call := srv.Events.List(id).Q(search).SingleEvents(true).OrderBy("startTime").
+			TimeMin(now.AddDate(0, 0, -7).Format(time.RFC3339)).TimeMax(now.AddDate(0, 1, 0).Format(time.RFC3339)).Context(ctx)
+		page, err := call.Do(callOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		return page.Items, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	matches := bestMatches(events.Items, search)
+	switch len(matches) {
+	case 0:
+		return "", nil, fmt.Errorf("no event matches %q", search)
+	case 1:
+		return eventCalendar(matches[0]), matches[0], nil
+	}
+
+	item, err := pickEvent(fmt.Sprintf("Several events match %q:", search), matches)
+	if err != nil {
+		return "", nil, err
+	}
+	return eventCalendar(item), item, nil
+}
+
+// findEvent resolves an argument that is either an event ID or a title,
+// trying it as an ID first.
+func findEvent(ctx context.Context, srv *calendar.Service, arg string) (string, *calendar.Event, error) {
+	calendarID, item, err := resolveEvent(ctx, srv, arg, "")
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) && (gerr.Code == http.StatusNotFound || gerr.Code == http.StatusBadRequest) {
+		return resolveEvent(ctx, srv, "", arg)
+	}
+	return calendarID, item, err
+}
+
+// pickEvent lists items under title and asks which one to use, the first
+// by default.
+func pickEvent(title string, items []*calendar.Event) (*calendar.Event, error) {
+	fmt.Println(title)
+	for i, item := range items {
+		fmt.Printf("  %d. %s  %s\n", i+1, eventWhen(item), item.Summary)
+	}
+	answer, err := ask(stdin, os.Stdout, "Which one", "1")
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(items) {
+		return nil, fmt.Errorf("no event numbered %q", answer)
+	}
+	return items[n-1], nil
+}
+
+// matchScore rates how well summary matches query, ignoring case: 4 for the
+// same text, 3 for a prefix, 2 for a substring, 1 when the query's letters
+// appear in order and 0 otherwise.
+func matchScore(summary, query string) int {
+	s, q := strings.ToLower(summary), strings.ToLower(query)
+	switch {
+	case s == q:
+		return 4
+	case strings.HasPrefix(s, q):
+		return 3
+	case strings.Contains(s, q):
+		return 2
+	}
+	rest := s
+	for _, r := range q {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return 0
+		}
+		rest = rest[i+len(string(r)):]
+	}
+	return 1
+}
+
+// bestMatches returns the events whose summary scores highest against
+// query. The API's full-text search also matches descriptions and guests;
+// events whose summary does not match at all are dropped.
+func bestMatches(items []*calendar.Event, query string) []*calendar.Event {
+	best := 0
+	var matches []*calendar.Event
+	for _, item := range items {
+		score := matchScore(item.Summary, query)
+		switch {
+		case score == 0:
+		case score > best:
+			best, matches = score, []*calendar.Event{item}
+		case score == best:
+			matches = append(matches, item)
+		}
+	}
+	return matches
+}
+
+// confirm asks a yes/no question, defaulting to no.
+func confirm(question string) (bool, error) {
+	answer, err := ask(stdin, os.Stdout, question+" [y/N]", "")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The backoff between attempts doubles from retryBaseDelay up to
+// retryMaxDelay.
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// retryTransport retries API requests that failed for reasons worth waiting
+// out: rate limits, and server errors where sending the request again cannot
+// repeat its effect. Up to attempts tries are made in all.
+type retryTransport struct {
+	base     http.RoundTripper
+	attempts int
+}
+
+// retryClient returns a copy of client whose requests go through
+// retryTransport, --max-attempts tries each.
+func retryClient(client *http.Client) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := *client
+	c.Transport = &retryTransport{base: base, attempts: *maxAttempts}
+	return &c
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.attempts || !retryable(req, resp) {
+			return resp, err
+		}
+		// A body that cannot be sent again ends the retries.
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		delay := retryDelay(attempt, resp)
+		resp.Body.Close()
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether resp is a 429 or one of the 403s the API sends
+// for rate limits, which refuse the request before it is carried out, or a
+// 5xx for an idempotent req. A 5xx can come after an insert went through, so
+// retrying a POST could create an event twice. The body is left readable.
+func retryable(req *http.Request, resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500:
+		return idempotent(req.Method)
+	case resp.StatusCode != http.StatusForbidden:
+		return false
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return err == nil && (bytes.Contains(b, []byte(`"rateLimitExceeded"`)) || bytes.Contains(b, []byte(`"userRateLimitExceeded"`)))
+}
+
+// idempotent reports whether sending a request with method twice has the
+// effect of sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryDelay is how long to wait after the given failed attempt: what the
+// server asks for in Retry-After, or an exponential backoff with jitter so
+// that parallel fetches do not retry in lockstep.
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, retryMaxDelay)
+	}
+	d := min(retryBaseDelay<<min(attempt-1, 8), retryMaxDelay)
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// responseGlyphs mark answers to invitations, in table rows and for each
+// guest in the TUI's detail view.
+var responseGlyphs = map[string]string{
+	"accepted":    "✓",
+	"declined":    "✗",
+	"tentative":   "~",
+	"needsAction": "?",
+}
+
+// selfResponse returns the user's answer to item, "" for events without
+// guests.
+func selfResponse(item *calendar.Event) string {
+	for _, a := range item.Attendees {
+		if a.Self {
+			return a.ResponseStatus
+		}
+	}
+	return ""
+}
+
+// rsvpSummary prefixes item's summary with the glyph of the user's answer,
+// after any conflict marker so that renderTable still finds it.
+func rsvpSummary(item *calendar.Event) string {
+	glyph := responseGlyphs[selfResponse(item)]
+	if glyph == "" {
+		return item.Summary
+	}
+	if rest, ok := strings.CutPrefix(item.Summary, conflictMarker); ok {
+		return conflictMarker + glyph + " " + rest
+	}
+	return glyph + " " + item.Summary
+}
+
+// hideDeclined drops the invitations the user declined unless
+// --show-declined is set.
+func hideDeclined(items []*calendar.Event) []*calendar.Event {
+	if *showDeclined {
+		return items
+	}
+	var kept []*calendar.Event
+	for _, item := range items {
+		if !declinedBySelf(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// The rsvp command's flags.
+var (
+	rsvpSearch  string
+	rsvpComment string
+	rsvpPropose string
+)
+
+// proposalComment turns --propose into a note for the organizer, keeping
+// item's length: "Could we move this to Thu Oct 15 15:00-16:00?".
+func proposalComment(item *calendar.Event, value string, now time.Time) (string, error) {
+	start, err := parseDate(value, now)
+	if err != nil {
+		return "", err
+	}
+	when := start.Format("Mon Jan 2 15:04")
+	if from, to, ok := eventTimes(item); ok {
+		when += "-" + start.Add(to.Sub(from)).Format("15:04")
+	} else if item.Start != nil && item.Start.Date != "" {
+		when = start.Format("Mon Jan 2")
+	}
+	return fmt.Sprintf("Could we move this to %s?", when), nil
+}
+
+var rsvpCmd = &cobra.Command{
+	Use:   "rsvp [EVENT-ID] accept|decline|tentative",
+	Short: "Respond to an invitation",
+	Long: "Respond to an invitation, optionally with a note to the organizer.\n\n" +
+		"When declining or answering tentatively, --propose suggests another time in the note. The length of the " +
+		"event is kept. The API cannot send Google Calendar's own time proposals, so the organizer sees it " +
+		"as a comment.",
+	Example: `  go-gcal-cli rsvp abc123 accept
+  go-gcal-cli rsvp --search "design review" decline --comment "Out sick"
+  go-gcal-cli rsvp abc123 decline --propose "tomorrow 15:00"`,
+	Args:      cobra.RangeArgs(1, 2),
+	ValidArgs: []string{"accept", "decline", "tentative"},
+	Run: func(cmd *cobra.Command, args []string) {
+		id, response := "", args[len(args)-1]
+		if len(args) == 2 {
+			id = args[0]
+		}
+		if _, ok := events.Responses[response]; !ok {
+			usageFatalf("Unknown response %q; use accept, decline or tentative", response)
+		}
+		if rsvpPropose != "" && response == "accept" {
+			usageFatalf("--propose only goes with decline or tentative")
+		}
+		requireScopes(writeScope)
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		calendarID, item, err := resolveEvent(ctx, srv, id, rsvpSearch)
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		comment := rsvpComment
+		if rsvpPropose != "" {
+			proposal, err := proposalComment(item, rsvpPropose, time.Now())
+			if err != nil {
+				usageFatalf("Invalid --propose: %v", err)
+			}
+			comment = strings.TrimSpace(comment + " " + proposal)
+		}
+		if _, err := events.RSVP(ctx, srv, calendarID, item, response, comment, callOptions()...); err != nil {
+			fatal("Unable to respond", scopeError(err))
+		}
+		fmt.Printf("Responded %s to %s  %s\n", events.Responses[response], eventWhen(item), item.Summary)
+		if rsvpPropose != "" {
+			fmt.Printf("Note: %s\n", comment)
+		}
+	},
+}
+
+func init() {
+	rsvpCmd.Flags().StringVar(&rsvpSearch, "search", "", "find the event by title instead of ID")
+	rsvpCmd.Flags().StringVar(&rsvpComment, "comment", "", "note to the organizer")
+	rsvpCmd.Flags().StringVar(&rsvpPropose, "propose", "", "suggest another start time in the note, e.g. \"tomorrow 15:00\"")
+	rootCmd.AddCommand(rsvpCmd)
+}
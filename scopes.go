@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/people/v1"
+)
+
+// scopeAliases maps the short names accepted by --scope to OAuth scopes.
+var scopeAliases = map[string]string{
+	"readonly":  calendar.CalendarReadonlyScope,
+	"events":    calendar.CalendarEventsScope,
+	"full":      calendar.CalendarScope,
+	"contacts":  people.ContactsReadonlyScope,
+	"directory": people.DirectoryReadonlyScope,
+}
+
+// legacyScopes is what tokens saved before scopes were recorded were
+// granted.
+var legacyScopes = []string{calendar.CalendarReadonlyScope}
+
+// writeScope is what commands that change the calendar need.
+const writeScope = calendar.CalendarEventsScope
+
+// commandScopes are added by commands that need more than read access; see
+// requireScopes.
+var commandScopes []string
+
+// requireScopes is called by a command before it authorizes, so that a
+// read-only token is upgraded before the first write instead of failing with
+// a 403.
+func requireScopes(scopes ...string) {
+	commandScopes = append(commandScopes, scopes...)
+}
+
+// resolveScopes expands --scope values into OAuth scope URLs, defaulting to
+// read-only access. With write set, or when a command asked for more via
+// requireScopes, the events scope is added unless already covered.
+func resolveScopes(values []string, write bool) ([]string, error) {
+	scopes, err := expandScopes(values)
+	if err != nil {
+		return nil, err
+	}
+	extra := commandScopes
+	if write {
+		extra = append(extra, writeScope)
+	}
+	for _, s := range extra {
+		if !coversScopes(scopes, []string{s}) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, nil
+}
+
+func expandScopes(values []string) ([]string, error) {
+	if len(values) == 0 {
+		return []string{calendar.CalendarReadonlyScope}, nil
+	}
+	var scopes []string
+	for _, v := range values {
+		if scope, ok := scopeAliases[v]; ok {
+			scopes = append(scopes, scope)
+			continue
+		}
+		if !strings.HasPrefix(v, "https://") {
+			return nil, fmt.Errorf("unknown scope %q; use readonly, events, full, contacts, directory or a scope URL", v)
+		}
+		scopes = append(scopes, v)
+	}
+	return scopes, nil
+}
+
+// coversScopes reports whether a token granted the scopes in granted can be
+// used for every scope in wanted. Full calendar access implies the narrower
+// calendar scopes.
+func coversScopes(granted, wanted []string) bool {
+	has := map[string]bool{}
+	for _, s := range granted {
+		has[s] = true
+	}
+	if has[calendar.CalendarScope] {
+		has[calendar.CalendarReadonlyScope] = true
+		has[calendar.CalendarEventsScope] = true
+		has[calendar.CalendarEventsReadonlyScope] = true
+	}
+	if has[calendar.CalendarEventsScope] {
+		has[calendar.CalendarEventsReadonlyScope] = true
+	}
+	for _, s := range wanted {
+		if !has[s] {
+			return false
+		}
+	}
+	return true
+}
+
+// grantedScopes returns the scopes the authorization server actually granted
+// tok, which can be fewer than requested when the user unticks a permission
+// on the consent screen.
+func grantedScopes(tok *oauth2.Token, requested []string) []string {
+	granted, _ := tok.Extra("scope").(string)
+	if granted == "" {
+		return requested
+	}
+	return strings.Fields(granted)
+}
+
+// errInsufficientScope marks API calls refused for lack of a scope.
+var errInsufficientScope = errors.New("the saved token does not grant the access this needs")
+
+// scopeError turns the API's 403 for a missing scope into an error that says
+// how to re-authorize. Other errors are returned as they are.
+func scopeError(err error) error {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) || gerr.Code != http.StatusForbidden {
+		return err
+	}
+	insufficient := strings.Contains(gerr.Message, "insufficient authentication scopes")
+	for _, item := range gerr.Errors {
+		if item.Reason == "insufficientPermissions" {
+			insufficient = true
+		}
+	}
+	if !insufficient {
+		return err
+	}
+	return fmt.Errorf("%w; re-authorize with \"auth login --write\" or --scope full (%v)", errInsufficientScope, err)
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func (l *stringList) Type() string {
+	return "strings"
+}
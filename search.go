@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// searchRegex is the search command's --regex flag.
+var searchRegex string
+
+// The search window when --from or --to is not given.
+const (
+	searchDaysBack  = 30
+	searchDaysAhead = 90
+)
+
+// searchWindow resolves --from and --to for search, from a month ago to
+// three months ahead unless given.
+func searchWindow(now time.Time) (time.Time, time.Time, error) {
+	from, to := startOfDay(now).AddDate(0, 0, -searchDaysBack), startOfDay(now).AddDate(0, 0, searchDaysAhead+1)
+	var err error
+	if *fromFlag != "" {
+		if from, err = parseDate(*fromFlag, now); err != nil {
+			return from, to, fmt.Errorf("--from: %w", err)
+		}
+	}
+	if *toFlag != "" {
+		if to, err = parseDate(*toFlag, now); err != nil {
+			return from, to, fmt.Errorf("--to: %w", err)
+		}
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("--to must be after --from")
+	}
+	return from, to, nil
+}
+
+// matchesPattern reports whether re matches the event's title, location or
+// description.
+func matchesPattern(item *calendar.Event, re *regexp.Regexp) bool {
+	return re.MatchString(item.Summary) || re.MatchString(item.Location) || re.MatchString(item.Description)
+}
+
+// runSearch prints the events in the window that the API finds for q and,
+// with --regex, that the pattern matches too. It reports whether any did.
+func runSearch(ctx context.Context, srv *calendar.Service, w io.Writer, q string, now time.Time) (bool, error) {
+	var re *regexp.Regexp
+	if searchRegex != "" {
+		var err error
+		// Titles are matched without regard to case unless the pattern
+		// says otherwise.
+		if re, err = regexp.Compile("(?i)" + searchRegex); err != nil {
+			return false, fmt.Errorf("--regex: %w", err)
+		}
+	}
+	from, to, err := searchWindow(now)
+	if err != nil {
+		return false, err
+	}
+	events, err := fetchMatching(ctx, srv, q, from, to)
+	if err != nil {
+		return false, err
+	}
+	var items []*calendar.Event
+	for _, item := range filterEvents(events.Items) {
+		if re == nil || matchesPattern(item, re) {
+			items = append(items, item)
+		}
+	}
+	if ok, err := writeEvents(w, items); ok {
+		return len(items) > 0, err
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !*noHeader {
+		fmt.Fprintln(tw, "WHEN\tSUMMARY\tID")
+	}
+	for _, item := range items {
+		summary := item.Summary
+		if multiCalendar() {
+			summary += " (" + calendarName(eventCalendar(item)) + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", eventWhen(item), summary, item.Id)
+	}
+	return true, tw.Flush()
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search [QUERY]",
+	Short: "Find events by text",
+	Long: fmt.Sprintf("Find the events whose title, description, location or guests contain QUERY, from %d days ago to %d "+
+		"days ahead unless --from and --to say otherwise. --regex keeps only the events whose title, location or "+
+		"description match a regular expression as well, and can stand in for QUERY.\n\n"+
+		"The events are printed with their dates and IDs, ready for show, edit or delete; --output picks another "+
+		"format. The exit status is 1 if nothing matches.", searchDaysBack, searchDaysAhead),
+	Example: `  go-gcal-cli search "design review"
+  go-gcal-cli search offsite --from -52w --to today
+  go-gcal-cli search --regex '^1:1 '`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var q string
+		if len(args) > 0 {
+			q = args[0]
+		}
+		if q == "" && searchRegex == "" {
+			usageFatalf("Give a query or --regex")
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		found, err := runSearch(ctx, srv, os.Stdout, q, time.Now())
+		if err != nil {
+			fatal("Unable to search events", err)
+		}
+		if !found {
+			fmt.Fprintln(os.Stderr, "No events match.")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchRegex, "regex", "", "only keep events whose title, location or description match this regular expression")
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// validScope checks a --scope value of edit or delete.
+func validScope(scope string) error {
+	if !slices.Contains(events.Scopes, scope) {
+		return fmt.Errorf("unknown scope %q; use one of %s", scope, strings.Join(events.Scopes, ", "))
+	}
+	return nil
+}
+
+// seriesScope narrows scope to what applies to item: non-recurring events
+// and series masters are always changed as a whole, and the following
+// occurrences from the first one on are all of them. The series master is
+// returned when the change is not to item alone.
+func seriesScope(ctx context.Context, srv *calendar.Service, calendarID string, item *calendar.Event, scope string) (string, *calendar.Event, error) {
+	switch {
+	case item.RecurringEventId == "":
+		return events.ScopeAll, nil, nil
+	case scope == events.ScopeThis:
+		return scope, nil, nil
+	}
+	master, err := srv.Events.Get(calendarID, item.RecurringEventId).Context(ctx).Do(callOptions()...)
+	if err != nil {
+		return "", nil, err
+	}
+	if scope == events.ScopeFollowing && item.OriginalStartTime != nil && master.Start != nil {
+		first, _ := eventStartTime(master.Start)
+		from, _ := eventStartTime(item.OriginalStartTime)
+		if !from.After(first) {
+			scope = events.ScopeAll
+		}
+	}
+	return scope, master, nil
+}
+
+// eventStartTime parses an API start or end, dates as local midnight.
+func eventStartTime(dt *calendar.EventDateTime) (time.Time, bool) {
+	if dt.Date != "" {
+		t, err := time.ParseInLocation("2006-01-02", dt.Date, time.Local)
+		return t, err == nil
+	}
+	return parseDateTime(dt)
+}
+
+// seriesPatch moves a time change made on occurrence onto master, so that
+// the whole series shifts by as much as the occurrence did and takes its
+// new length, as moving "all events" does in the web UI.
+func seriesPatch(patch, master, occurrence *calendar.Event) (*calendar.Event, error) {
+	if patch.Start == nil {
+		return patch, nil
+	}
+	oldStart, _, ok := eventTimes(occurrence)
+	masterStart, _, masterOK := eventTimes(master)
+	if !ok || !masterOK {
+		return nil, fmt.Errorf("changing the time of all-day series is not supported")
+	}
+	newStart, err := time.Parse(time.RFC3339, patch.Start.DateTime)
+	if err != nil {
+		return nil, err
+	}
+	newEnd, err := time.Parse(time.RFC3339, patch.End.DateTime)
+	if err != nil {
+		return nil, err
+	}
+	start := masterStart.Add(newStart.Sub(oldStart))
+	shifted := *patch
+	shifted.Start = &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: master.Start.TimeZone}
+	shifted.End = &calendar.EventDateTime{DateTime: start.Add(newEnd.Sub(newStart)).Format(time.RFC3339), TimeZone: master.End.TimeZone}
+	return &shifted, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// showWhen describes when item takes place in full: dates, times and the
+// event's own time zone where it differs from the local one.
+func showWhen(item *calendar.Event) string {
+	startTime, endTime, ok := eventTimes(item)
+	if !ok {
+		e := newEvent(item)
+		last := e.End.AddDate(0, 0, -1)
+		if !last.After(e.Start) {
+			return e.Start.Format("Mon 2006-01-02") + ", all day"
+		}
+		return e.Start.Format("Mon 2006-01-02") + " – " + last.Format("Mon 2006-01-02") + ", all day"
+	}
+	when := startTime.Format("Mon 2006-01-02 15:04") + "–" + endTime.Format("15:04")
+	if spansDays(startTime, endTime) {
+		when = startTime.Format("Mon 2006-01-02 15:04") + " → " + endTime.Format("Mon 2006-01-02 15:04")
+	}
+	return when + dualTime(item, startTime)
+}
+
+// responseWords spell out the answers to invitations.
+var responseWords = map[string]string{
+	"accepted":    "accepted",
+	"declined":    "declined",
+	"tentative":   "maybe",
+	"needsAction": "no answer",
+}
+
+// guestLine describes one attendee and their answer.
+func guestLine(a *calendar.EventAttendee) string {
+	guest := a.Email
+	if a.DisplayName != "" {
+		guest = a.DisplayName + " <" + a.Email + ">"
+	}
+	response := a.ResponseStatus
+	if _, ok := responseGlyphs[response]; !ok {
+		response = "needsAction"
+	}
+	guest = responseGlyphs[response] + " " + guest + " (" + responseWords[response]
+	if a.Optional {
+		guest += ", optional"
+	}
+	if a.Organizer {
+		guest += ", organizer"
+	}
+	return guest + ")"
+}
+
+// seriesRecurrence returns the recurrence rules of item, which for an
+// occurrence are those of its series. The series not being readable only
+// leaves them out.
+func seriesRecurrence(ctx context.Context, srv *calendar.Service, calendarID string, item *calendar.Event) []string {
+	if len(item.Recurrence) > 0 || item.RecurringEventId == "" {
+		return item.Recurrence
+	}
+	master, err := srv.Events.Get(calendarID, item.RecurringEventId).Fields("recurrence").Context(ctx).Do(callOptions()...)
+	if err != nil {
+		return nil
+	}
+	return master.Recurrence
+}
+
+// writeEventDetail prints everything about item, the description last.
+func writeEventDetail(w io.Writer, item *calendar.Event, calendarID string, recurrence []string) error {
+	if *outputFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(item)
+	}
+	fields := [][2]string{
+		{"Summary", item.Summary},
+		{"When", showWhen(item)},
+		{"Repeats", describeRecurrence(recurrence)},
+		{"Location", item.Location},
+		{"Calendar", calendarName(calendarID)},
+		{"Status", item.Status},
+	}
+	if item.Organizer != nil {
+		organizer := item.Organizer.Email
+		if item.Organizer.DisplayName != "" {
+			organizer = item.Organizer.DisplayName + " <" + organizer + ">"
+		}
+		fields = append(fields, [2]string{"Organizer", organizer})
+	}
+	if item.ConferenceData != nil {
+		for _, ep := range item.ConferenceData.EntryPoints {
+			join := ep.EntryPointType + ": " + ep.Uri
+			if ep.Pin != "" {
+				join += " (PIN " + ep.Pin + ")"
+			}
+			fields = append(fields, [2]string{"Join", join})
+		}
+	} else if link := eventLink(item, *linkSource); link != "" {
+		fields = append(fields, [2]string{"Join", link})
+	}
+	for _, a := range item.Attendees {
+		fields = append(fields, [2]string{fmt.Sprintf("Guests (%d)", len(item.Attendees)), guestLine(a)})
+	}
+	for _, att := range item.Attachments {
+		fields = append(fields, [2]string{"Files", att.Title + " " + att.FileUrl})
+	}
+	for _, stamp := range [][2]string{{"Created", item.Created}, {"Updated", item.Updated}} {
+		if t, err := time.Parse(time.RFC3339, stamp[1]); err == nil {
+			fields = append(fields, [2]string{stamp[0], t.Local().Format("Mon 2006-01-02 15:04")})
+		}
+	}
+	fields = append(fields, [2]string{"ID", item.Id}, [2]string{"Link", item.HtmlLink})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	last := ""
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		// Repeated labels, as for guests, are printed once.
+		label := field[0] + ":"
+		if field[0] == last {
+			label = ""
+		}
+		last = field[0]
+		fmt.Fprintf(tw, "%s\t%s\n", label, field[1])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if desc := descriptionText(item.Description); desc != "" {
+		fmt.Fprintf(w, "\n%s\n", desc)
+	}
+	return nil
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show EVENT",
+	Short: "Show everything about an event",
+	Long: "Show an event, given by ID or by title, in full: when and how often it takes place, where and how to join, " +
+		"the guests and their answers, attachments, when it was created and last changed, its Google Calendar link " +
+		"and the description as plain text.\n\n" +
+		"A title is looked up among the events from a week ago to a month ahead, asking which one is meant when " +
+		"several match. --output json prints the event as the API returns it.",
+	Example: `  go-gcal-cli show 3hk0qnlm2gm5rds1u9a4v8c0p8
+  go-gcal-cli show "design review"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		calendarID, item, err := findEvent(ctx, srv, args[0])
+		if err != nil {
+			fatal("Unable to find event", err)
+		}
+		if err := writeEventDetail(os.Stdout, item, calendarID, seriesRecurrence(ctx, srv, calendarID, item)); err != nil {
+			fatal("Unable to show event", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
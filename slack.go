@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zalando/go-keyring"
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	slackTokenEnv   = "GCAL_SLACK_TOKEN"
+	slackProfileURL = "https://slack.com/api/users.profile.set"
+	slackEmoji      = ":calendar:"
+	slackTimeout    = 30 * time.Second
+)
+
+// slackTokenUser is the keychain entry holding the active profile's Slack
+// token, next to its Google token.
+func slackTokenUser() string {
+	return profileFileName("slack", "", activeProfile())
+}
+
+// slackToken returns the Slack user token from $GCAL_SLACK_TOKEN or the
+// keychain, where "slack login" puts it.
+func slackToken() (string, error) {
+	if tok := os.Getenv(slackTokenEnv); tok != "" {
+		return tok, nil
+	}
+	tok, err := keyring.Get(keyringService, slackTokenUser())
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("no Slack token; run \"slack login\" or set %s", slackTokenEnv)
+	}
+	return tok, err
+}
+
+// slackStatus keeps the user's Slack status in step with their meetings.
+// It only clears a status it set itself.
+type slackStatus struct {
+	token  string
+	client *http.Client
+	// text is the status last set, "" when none is.
+	text string
+}
+
+// setProfile calls users.profile.set. expires clears the status on Slack's
+// side even if the watcher is gone by then.
+func (s *slackStatus) setProfile(ctx context.Context, text, emoji string, expires time.Time) error {
+	profile := map[string]any{"status_text": text, "status_emoji": emoji, "status_expiration": 0}
+	if !expires.IsZero() {
+		profile["status_expiration"] = expires.Unix()
+	}
+	body, err := json.Marshal(map[string]any{"profile": profile})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackProfileURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("slack: %s", resp.Status)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack: %s", result.Error)
+	}
+	return nil
+}
+
+// sync sets the status to "In a meeting until 15:00" while any of ongoing
+// blocks time, and clears it once none does.
+func (s *slackStatus) sync(ctx context.Context, ongoing []*calendar.Event) error {
+	var until time.Time
+	for _, item := range ongoing {
+		if _, end, ok := eventTimes(item); ok && blocksTime(item) && end.After(until) {
+			until = end
+		}
+	}
+	if until.IsZero() {
+		if s.text == "" {
+			return nil
+		}
+		if err := s.setProfile(ctx, "", "", time.Time{}); err != nil {
+			return err
+		}
+		s.text = ""
+		return nil
+	}
+	text := "In a meeting until " + until.Local().Format("15:04")
+	if text == s.text {
+		return nil
+	}
+	if err := s.setProfile(ctx, text, slackEmoji, until); err != nil {
+		return err
+	}
+	s.text = text
+	return nil
+}
+
+var slackCmd = &cobra.Command{
+	Use:   "slack",
+	Short: "Set up the Slack status integration of watch and daemon",
+	Long: "With --slack-status, watch and daemon set your Slack status to \"In a meeting until 15:00\" during meetings " +
+		"and clear it afterwards.\n\n" +
+		"They need a Slack user token with the users.profile:write scope, stored with \"slack login\" or given in $" +
+		slackTokenEnv + ".",
+}
+
+var slackLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store a Slack user token in the OS keychain",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		tok, err := ask(stdin, os.Stdout, "Slack user token (xoxp-...)", "")
+		if err != nil {
+			fatal("Unable to read token", err)
+		}
+		tok = strings.TrimSpace(tok)
+		if !strings.HasPrefix(tok, "xox") {
+			log.Fatalf("That does not look like a Slack token")
+		}
+		if err := keyring.Set(keyringService, slackTokenUser(), tok); err != nil {
+			fatal("Unable to store token", err)
+		}
+		fmt.Println("Saved Slack token to the OS keychain.")
+	},
+}
+
+var slackLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored Slack token",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := keyring.Delete(keyringService, slackTokenUser())
+		if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			fatal("Unable to remove token", err)
+		}
+		fmt.Println("Removed Slack token.")
+	},
+}
+
+func init() {
+	slackCmd.AddCommand(slackLoginCmd, slackLogoutCmd)
+	rootCmd.AddCommand(slackCmd)
+}
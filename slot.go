@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// slotStep is what free slots are aligned to, so that a gap starting at
+// 10:07 is offered from 10:15.
+const slotStep = 15 * time.Minute
+
+// workingHours is the part of each day meetings may be booked into, given as
+// minutes after midnight, on the days set in days.
+type workingHours struct {
+	start, end int
+	days       [7]bool // by time.Weekday
+}
+
+// configuredHours returns the working hours and days set with
+// --working-hours and --working-days.
+func configuredHours() (workingHours, error) {
+	h, err := parseWorkingHours(*workHours)
+	if err != nil {
+		return h, err
+	}
+	if h.days, err = parseWorkingDays(*workDays); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// parseWorkingDays parses days such as "mon-fri", "mon,tue,thu" or
+// "sun-thu" into a set.
+func parseWorkingDays(value string) ([7]bool, error) {
+	var days [7]bool
+	for _, part := range strings.Split(strings.ToLower(value), ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, ok1 := parseWeekday(strings.TrimSpace(from))
+		last, ok2 := first, true
+		if isRange {
+			last, ok2 = parseWeekday(strings.TrimSpace(to))
+		}
+		if !ok1 || !ok2 {
+			return days, fmt.Errorf("working days %q must look like mon-fri or mon,tue,thu", value)
+		}
+		// Ranges may wrap around the week, as in "sun-thu" or "fri-mon".
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// outOfHoursModes are the values --out-of-hours accepts.
+var outOfHoursModes = []string{"show", "dim", "hide"}
+
+func validOutOfHours(mode string) error {
+	if !slices.Contains(outOfHoursModes, mode) {
+		return fmt.Errorf("unknown mode %q; use one of %s", mode, strings.Join(outOfHoursModes, ", "))
+	}
+	return nil
+}
+
+// outside reports whether item is a timed event that does not overlap the
+// working hours at all.
+func (h workingHours) outside(item *calendar.Event) bool {
+	start, end, ok := eventTimes(item)
+	return ok && len(h.windows(start, end)) == 0
+}
+
+// parseWorkingHours parses a range such as "09:00-17:00" or "9am-5pm".
+func parseWorkingHours(value string) (workingHours, error) {
+	from, to, ok := strings.Cut(strings.ToLower(value), "-")
+	if !ok {
+		return workingHours{}, fmt.Errorf("working hours %q must look like 09:00-17:00", value)
+	}
+	sh, sm, ok1 := parseClock(strings.TrimSpace(from))
+	eh, em, ok2 := parseClock(strings.TrimSpace(to))
+	if !ok1 || !ok2 {
+		return workingHours{}, fmt.Errorf("working hours %q must look like 09:00-17:00", value)
+	}
+	h := workingHours{start: sh*60 + sm, end: eh*60 + em}
+	if h.end <= h.start {
+		return h, fmt.Errorf("working hours %q end before they start", value)
+	}
+	return h, nil
+}
+
+// windows returns the working hours on each day between from and to,
+// clipped to that range.
+func (h workingHours) windows(from, to time.Time) []period {
+	var windows []period
+	for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !h.days[day.Weekday()] {
+			continue
+		}
+		p := period{Start: atClock(day, h.start/60, h.start%60), End: atClock(day, h.end/60, h.end%60)}
+		if p.Start.Before(from) {
+			p.Start = from
+		}
+		if p.End.After(to) {
+			p.End = to
+		}
+		if p.End.After(p.Start) {
+			windows = append(windows, p)
+		}
+	}
+	return windows
+}
+
+// mergeBusy sorts busy periods and joins the ones that overlap or touch.
+func mergeBusy(busy []period) []period {
+	sorted := append([]period(nil), busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+	var merged []period
+	for _, p := range sorted {
+		if n := len(merged); n > 0 && !p.Start.After(merged[n-1].End) {
+			if p.End.After(merged[n-1].End) {
+				merged[n-1].End = p.End
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// freeSlots returns the gaps of at least d that windows leave between busy
+// periods, each starting on a slotStep boundary.
+func freeSlots(windows, busy []period, d time.Duration) []period {
+	busy = mergeBusy(busy)
+	var free []period
+	for _, w := range windows {
+		start := w.Start
+		for _, b := range busy {
+			if !b.End.After(start) || !b.Start.Before(w.End) {
+				continue
+			}
+			free = appendSlot(free, period{Start: start, End: b.Start}, d)
+			start = b.End
+		}
+		free = appendSlot(free, period{Start: start, End: w.End}, d)
+	}
+	return free
+}
+
+// appendSlot adds p to free if, once aligned to slotStep, it still fits d.
+func appendSlot(free []period, p period, d time.Duration) []period {
+	if aligned := p.Start.Truncate(slotStep); aligned.Before(p.Start) {
+		p.Start = aligned.Add(slotStep)
+	}
+	if p.End.Sub(p.Start) >= d {
+		free = append(free, p)
+	}
+	return free
+}
+
+// The slot command's flags.
+var (
+	slotAttendees []string
+	slotDuration  time.Duration
+	slotWithin    string
+	slotWeekends  bool
+	slotBook      bool
+	slotTitle     string
+	slotMeet      bool
+)
+
+var slotCmd = &cobra.Command{
+	Use:   "slot",
+	Short: "Find times that work for you and every attendee",
+	Long: "Look up everyone's free/busy and print the gaps of at least --duration that fall into --working-hours " +
+		"within --within, on --working-days and on weekends too with --weekends. The selected calendars count as " +
+		"yours.\n\n" +
+		"With --book the first slot is booked with the attendees invited.",
+	Example: `  go-gcal-cli slot --attendees a@example.com,b@example.com --duration 30m --within "next 3 days" --working-hours 09:00-17:00
+  go-gcal-cli slot --attendees a@example.com --duration 1h --book --title "Planning" --meet`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		now := time.Now()
+		hours, err := configuredHours()
+		if err != nil {
+			usageFatalf("Invalid working hours: %v", err)
+		}
+		if slotWeekends {
+			hours.days[time.Saturday], hours.days[time.Sunday] = true, true
+		}
+		end, err := parseWithin(slotWithin, now)
+		if err != nil {
+			fatal("Invalid --within", &UsageError{err})
+		}
+		if slotDuration <= 0 {
+			usageFatalf("--duration must be positive")
+		}
+
+		if slotBook {
+			requireScopes(writeScope)
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		ids := slices.Concat(calendarIDs(), slotAttendees)
+		result, err := queryFreeBusy(ctx, srv, ids, now, end)
+		if err != nil {
+			fatal("Unable to query free/busy", err)
+		}
+		var busy []period
+		for _, b := range result {
+			if b.Err != "" {
+				fmt.Fprintf(os.Stderr, "No free/busy for %s (%s); slots may clash with their meetings.\n", b.ID, b.Err)
+			}
+			busy = append(busy, b.Busy...)
+		}
+
+		slots := freeSlots(hours.windows(now, end), busy, slotDuration)
+		if len(slots) == 0 {
+			fmt.Printf("No %s slot works for everyone before %s.\n", shortDuration(slotDuration), end.Format("Mon 2006-01-02 15:04"))
+			os.Exit(1)
+		}
+		if !slotBook {
+			for _, p := range slots {
+				fmt.Println(p)
+			}
+			return
+		}
+
+		spec := events.Spec{
+			Title:     slotTitle,
+			Start:     slots[0].Start,
+			Duration:  slotDuration,
+			TimeZone:  *tzFlag,
+			Attendees: slotAttendees,
+			Meet:      slotMeet,
+		}
+		item, err := spec.Event()
+		if err != nil {
+			fatal("Invalid event", &UsageError{err})
+		}
+		created, err := events.Insert(ctx, srv, calendarIDs()[0], item, callOptions()...)
+		if err != nil {
+			fatal("Unable to create event", scopeError(err))
+		}
+		printCreated(created)
+	},
+}
+
+func init() {
+	f := slotCmd.Flags()
+	f.StringSliceVar(&slotAttendees, "attendees", nil, "comma-separated email addresses who must be free")
+	f.DurationVar(&slotDuration, "duration", 30*time.Minute, "how long the meeting lasts")
+	f.StringVar(&slotWithin, "within", "next 3 days", "how far ahead to look (e.g. \"next 5 days\", 2w, 48h, friday)")
+	f.BoolVar(&slotWeekends, "weekends", false, "offer slots on Saturdays and Sundays too")
+	f.BoolVar(&slotBook, "book", false, "create the event in the first slot and invite the attendees")
+	f.StringVar(&slotTitle, "title", "Meeting", "title of the event --book creates")
+	f.BoolVar(&slotMeet, "meet", false, "add a Google Meet link to the event --book creates")
+	rootCmd.AddCommand(slotCmd)
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// statsWeek is the stats command's --week flag: a day of the week to report
+// on.
+var statsWeek string
+
+// backToBackGap is the most time between two meetings that still makes them
+// back to back.
+const backToBackGap = 5 * time.Minute
+
+// statsTop is how many recurring meetings the report lists.
+const statsTop = 5
+
+// meetingStats is the stats report, also its --output json form. Durations
+// are in hours.
+type meetingStats struct {
+	From          time.Time    `json:"from"`
+	To            time.Time    `json:"to"`
+	Meetings      int          `json:"meetings"`
+	MeetingHours  float64      `json:"meeting_hours"`
+	WorkingHours  float64      `json:"working_hours"`
+	FreeHours     float64      `json:"free_hours"`
+	BackToBack    int          `json:"back_to_back_streaks"`
+	LongestStreak int          `json:"longest_streak"`
+	BusiestDay    string       `json:"busiest_day,omitempty"`
+	BusiestHours  float64      `json:"busiest_day_hours"`
+	TopRecurring  []statsGroup `json:"top_recurring"`
+	ByCalendar    []statsGroup `json:"by_calendar"`
+}
+
+// statsGroup totals the meetings under one name.
+type statsGroup struct {
+	Name     string  `json:"name"`
+	Meetings int     `json:"meetings"`
+	Hours    float64 `json:"hours"`
+}
+
+// sortedGroups orders groups by time spent, most first.
+func sortedGroups(groups map[string]*statsGroup) []statsGroup {
+	list := make([]statsGroup, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, *g)
+	}
+	slices.SortFunc(list, func(a, b statsGroup) int {
+		return cmp.Or(cmp.Compare(b.Hours, a.Hours), cmp.Compare(b.Meetings, a.Meetings), cmp.Compare(a.Name, b.Name))
+	})
+	return list
+}
+
+// countedMeetings returns the timed events between from and to that take
+// up time, each meeting once even when it is on several calendars.
+func countedMeetings(items []*calendar.Event, from, to time.Time) []*calendar.Event {
+	var meetings []*calendar.Event
+	for _, item := range items {
+		start, end, ok := eventTimes(item)
+		if !ok || !blocksTime(item) || !start.Before(to) || !end.After(from) {
+			continue
+		}
+		if slices.ContainsFunc(meetings, func(m *calendar.Event) bool { return sameEvent(m, item) }) {
+			continue
+		}
+		meetings = append(meetings, item)
+	}
+	return meetings
+}
+
+// computeStats analyses the meetings between from and to against the
+// working hours.
+func computeStats(items []*calendar.Event, from, to time.Time, hours workingHours) meetingStats {
+	s := meetingStats{From: from, To: to}
+	meetings := countedMeetings(items, from, to)
+	s.Meetings = len(meetings)
+
+	var busy []period
+	recurring, calendars := map[string]*statsGroup{}, map[string]*statsGroup{}
+	perDay := map[time.Time]time.Duration{}
+	for _, item := range meetings {
+		start, end, _ := eventTimes(item)
+		start, end = maxTime(start, from), minTime(end, to)
+		busy = append(busy, period{Start: start, End: end})
+		perDay[startOfDay(start.Local())] += end.Sub(start)
+		add := func(groups map[string]*statsGroup, key, name string) {
+			g, ok := groups[key]
+			if !ok {
+				g = &statsGroup{Name: name}
+				groups[key] = g
+			}
+			g.Meetings++
+			g.Hours += end.Sub(start).Hours()
+		}
+		if item.RecurringEventId != "" {
+			add(recurring, item.RecurringEventId, item.Summary)
+		}
+		calendarID := eventCalendar(item)
+		add(calendars, calendarID, calendarName(calendarID))
+	}
+
+	// Overlapping meetings only take up the time once.
+	busy = mergeBusy(busy)
+	for _, p := range busy {
+		s.MeetingHours += p.End.Sub(p.Start).Hours()
+	}
+	for _, w := range hours.windows(from, to) {
+		s.WorkingHours += w.End.Sub(w.Start).Hours()
+		for _, p := range busy {
+			if start, end := maxTime(p.Start, w.Start), minTime(p.End, w.End); end.After(start) {
+				s.FreeHours -= end.Sub(start).Hours()
+			}
+		}
+	}
+	s.FreeHours += s.WorkingHours
+
+	// A streak is a run of meetings each starting at most backToBackGap
+	// after the one before ended.
+	slices.SortFunc(meetings, func(a, b *calendar.Event) int { return eventStart(a).Compare(eventStart(b)) })
+	streak := 1
+	var streakEnd time.Time
+	for i, item := range meetings {
+		start, end, _ := eventTimes(item)
+		if i > 0 && start.Sub(streakEnd) <= backToBackGap && startOfDay(start.Local()).Equal(startOfDay(streakEnd.Local())) {
+			streak++
+			if streak == 2 {
+				s.BackToBack++
+			}
+		} else {
+			streak = 1
+		}
+		s.LongestStreak = max(s.LongestStreak, streak)
+		if streak == 1 || end.After(streakEnd) {
+			streakEnd = end
+		}
+	}
+
+	for day, d := range perDay {
+		if d.Hours() > s.BusiestHours || d.Hours() == s.BusiestHours && day.Format("2006-01-02") < s.BusiestDay {
+			s.BusiestDay, s.BusiestHours = day.Format("2006-01-02"), d.Hours()
+		}
+	}
+	s.TopRecurring = sortedGroups(recurring)
+	if len(s.TopRecurring) > statsTop {
+		s.TopRecurring = s.TopRecurring[:statsTop]
+	}
+	s.ByCalendar = sortedGroups(calendars)
+	return s
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// hoursText renders hours as "12h30m".
+func hoursText(h float64) string {
+	return shortDuration(time.Duration(h * float64(time.Hour)))
+}
+
+// writeStats prints the report.
+func writeStats(w io.Writer, s meetingStats) error {
+	if *outputFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	fmt.Fprintln(w, CaptionStyle.Render(fmt.Sprintf("%s – %s", s.From.Format("Mon Jan 2"), s.To.Add(-time.Nanosecond).Format("Mon Jan 2"))))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Meetings:\t%d\n", s.Meetings)
+	fmt.Fprintf(tw, "In meetings:\t%s\n", hoursText(s.MeetingHours))
+	if s.WorkingHours > 0 {
+		fmt.Fprintf(tw, "Free:\t%s of %s working hours (%.0f%%)\n", hoursText(s.FreeHours), hoursText(s.WorkingHours), 100*s.FreeHours/s.WorkingHours)
+	}
+	fmt.Fprintf(tw, "Back to back:\t%d (longest run: %d meetings)\n", s.BackToBack, s.LongestStreak)
+	if s.BusiestDay != "" {
+		day, _ := time.ParseInLocation("2006-01-02", s.BusiestDay, time.Local)
+		fmt.Fprintf(tw, "Busiest day:\t%s, %s\n", day.Format("Monday"), hoursText(s.BusiestHours))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, section := range []struct {
+		title  string
+		groups []statsGroup
+	}{{"Top recurring meetings", s.TopRecurring}, {"By calendar", s.ByCalendar}} {
+		if len(section.groups) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, "\n"+CaptionStyle.Render(section.title))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, g := range section.groups {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\n", g.Name, g.Meetings, hoursText(g.Hours))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statsWindow resolves the week to report on: Monday to Monday around
+// --week, or --from and --to when given.
+func statsWindow(now time.Time) (time.Time, time.Time, error) {
+	if *fromFlag != "" || *toFlag != "" {
+		return freebusyWindow(now)
+	}
+	day, err := parseDate(statsWeek, now)
+	if err != nil {
+		return day, day, fmt.Errorf("--week: %w", err)
+	}
+	monday := startOfDay(day).AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	return monday, monday.AddDate(0, 0, 7), nil
+}
+
+// runStats fetches the window's events and prints the report.
+func runStats(ctx context.Context, srv *calendar.Service, w io.Writer, now time.Time) error {
+	from, to, err := statsWindow(now)
+	if err != nil {
+		return err
+	}
+	hours, err := configuredHours()
+	if err != nil {
+		return err
+	}
+	events, err := fetchEvents(ctx, srv, from, to)
+	if err != nil {
+		return err
+	}
+	warnStale(os.Stderr)
+	return writeStats(w, computeStats(events.Items, from, to, hours))
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize a week's meeting load",
+	Long: "Report on the week around --week, Monday to Sunday, or on --from to --to: the number of meetings and the " +
+		"time spent in them, the free part of --working-hours, runs of back-to-back meetings, the busiest day, the " +
+		"recurring meetings taking the most time and the time per calendar.\n\n" +
+		"Meetings marked free and declined invitations do not count, and overlapping meetings count their time " +
+		"once. --output json prints the report as JSON.",
+	Example: `  go-gcal-cli stats --week
+  go-gcal-cli stats --week="last week" --output json
+  go-gcal-cli stats --from -30d --to today --all-calendars`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := runStats(ctx, srv, os.Stdout, time.Now()); err != nil {
+			fatal("Unable to compute stats", err)
+		}
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsWeek, "week", "today", "any day of the week to report on, given as --week=DAY (e.g. \"last week\", -14d)")
+	statsCmd.Flags().Lookup("week").NoOptDefVal = "today"
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The status command's flags.
+var (
+	statusStyle  string
+	statusWidth  int
+	statusWithin time.Duration
+	statusWarn   time.Duration
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print a one-line status of the current or next meeting for status bars",
+	Long: "Print a one-line status of the meeting that is on now, or of the next one if it starts within --within.\n\n" +
+		"Nothing is printed when there is neither, so status bars hide the segment.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		render, ok := statusStyles[statusStyle]
+		if !ok {
+			usageFatalf("Unknown --style %q; use one of %s", statusStyle, strings.Join(statusStyleNames(), ", "))
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		s, err := currentStatus(ctx, srv, time.Now(), statusStyle == "waybar")
+		if err != nil {
+			fatal("Unable to get meeting status", err)
+		}
+		if err := render(os.Stdout, s); err != nil {
+			fatal("Unable to write status", err)
+		}
+	},
+}
+
+// meetingStatus is what a status line describes.
+type meetingStatus struct {
+	Event   *calendar.Event // nil when there is nothing to show
+	Ongoing bool
+	Left    time.Duration     // until the end when ongoing, else until the start
+	Today   []*calendar.Event // the rest of today, for tooltips
+}
+
+// Urgent reports whether the next meeting starts within --warn.
+func (s meetingStatus) Urgent() bool {
+	return s.Event != nil && !s.Ongoing && s.Left <= statusWarn
+}
+
+// Text is the plain status line, e.g. "▶ Standup in 7m".
+func (s meetingStatus) Text() string {
+	if s.Event == nil {
+		return ""
+	}
+	summary := truncate(s.Event.Summary, statusWidth)
+	if s.Ongoing {
+		return fmt.Sprintf("● %s %s left", summary, shortDuration(s.Left))
+	}
+	return fmt.Sprintf("▶ %s in %s", summary, shortDuration(s.Left))
+}
+
+// shortDuration renders d rounded to minutes as 7m or 1h05m.
+func shortDuration(d time.Duration) string {
+	m := int(d.Round(time.Minute).Minutes())
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
+
+// ongoingMeeting returns the first of items in progress at now that the
+// user has not declined, or nil.
+func ongoingMeeting(items []*calendar.Event, now time.Time) *calendar.Event {
+	for _, item := range items {
+		startTime, endTime, ok := eventTimes(item)
+		if ok && !declinedBySelf(item) && isOngoing(now, startTime, endTime) {
+			return item
+		}
+	}
+	return nil
+}
+
+// currentStatus finds the meeting in progress, or else the next one if it
+// starts within --within. Declined invitations are ignored. With agenda set
+// the rest of today's events are fetched as well.
+func currentStatus(ctx context.Context, srv *calendar.Service, now time.Time, agenda bool) (meetingStatus, error) {
+	_, events, err := findUpcoming(ctx, srv, now, func(items []*calendar.Event) *calendar.Event {
+		if item := ongoingMeeting(items, now); item != nil {
+			return item
+		}
+		return nextMeetingEvent(items, now)
+	})
+	if err != nil {
+		return meetingStatus{}, err
+	}
+	var s meetingStatus
+	if agenda {
+		today, err := fetchEvents(ctx, srv, now, startOfDay(now).AddDate(0, 0, 1))
+		if err != nil {
+			return s, err
+		}
+		for _, item := range today.Items {
+			if _, _, ok := eventTimes(item); ok && !declinedBySelf(item) {
+				s.Today = append(s.Today, item)
+			}
+		}
+	}
+
+	if item := ongoingMeeting(events.Items, now); item != nil {
+		_, endTime, _ := eventTimes(item)
+		s.Event, s.Ongoing, s.Left = item, true, endTime.Sub(now)
+		return s, nil
+	}
+	if item := nextMeetingEvent(events.Items, now); item != nil {
+		startTime, _, _ := eventTimes(item)
+		if statusWithin <= 0 || startTime.Sub(now) <= statusWithin {
+			s.Event, s.Left = item, startTime.Sub(now)
+		}
+	}
+	return s, nil
+}
+
+// Class names the state for status bars that style by CSS class: ongoing,
+// urgent, upcoming or idle.
+func (s meetingStatus) Class() string {
+	switch {
+	case s.Event == nil:
+		return "idle"
+	case s.Ongoing:
+		return "ongoing"
+	case s.Urgent():
+		return "urgent"
+	}
+	return "upcoming"
+}
+
+// Tooltip lists the rest of today's meetings, one per line.
+func (s meetingStatus) Tooltip() string {
+	if len(s.Today) == 0 {
+		return "No more meetings today"
+	}
+	var lines []string
+	for _, item := range s.Today {
+		lines = append(lines, formatTimeRange(item)+"  "+item.Summary)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statusStyles render a status line for a particular status bar.
+var statusStyles = map[string]func(w io.Writer, s meetingStatus) error{
+	"plain": func(w io.Writer, s meetingStatus) error {
+		_, err := fmt.Fprintln(w, s.Text())
+		return err
+	},
+	"tmux": func(w io.Writer, s meetingStatus) error {
+		text := strings.ReplaceAll(s.Text(), "#", "##")
+		switch s.Class() {
+		case "urgent":
+			text = "#[fg=colour196,bold]" + text + "#[default]"
+		case "ongoing":
+			text = "#[fg=colour39]" + text + "#[default]"
+		case "upcoming":
+			text = "#[fg=colour46]" + text + "#[default]"
+		}
+		_, err := fmt.Fprintln(w, text)
+		return err
+	},
+	// waybar is the JSON a custom module with "return-type": "json" expects.
+	"waybar": func(w io.Writer, s meetingStatus) error {
+		return json.NewEncoder(w).Encode(struct {
+			Text    string `json:"text"`
+			Tooltip string `json:"tooltip"`
+			Class   string `json:"class"`
+		}{s.Text(), s.Tooltip(), s.Class()})
+	},
+	"polybar": func(w io.Writer, s meetingStatus) error {
+		text := s.Text()
+		switch s.Class() {
+		case "urgent":
+			text = "%{F#ff5555}" + text + "%{F-}"
+		case "ongoing":
+			text = "%{F#62d9f5}" + text + "%{F-}"
+		}
+		_, err := fmt.Fprintln(w, text)
+		return err
+	},
+}
+
+func statusStyleNames() []string {
+	var names []string
+	for name := range statusStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	statusCmd.Flags().StringVar(&statusStyle, "style", "plain", "status bar to format for: plain, tmux, waybar or polybar")
+	statusCmd.Flags().IntVar(&statusWidth, "max-width", 30, "shorten summaries longer than this")
+	statusCmd.Flags().DurationVar(&statusWithin, "within", time.Hour, "only show the next meeting once it starts this soon; 0 shows it always")
+	statusCmd.Flags().DurationVar(&statusWarn, "warn", 5*time.Minute, "highlight the next meeting once it starts this soon")
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+	"gopkg.in/yaml.v3"
+)
+
+// noColorEnv turns styling off when set to anything; see https://no-color.org.
+const noColorEnv = "NO_COLOR"
+
+// themesSetting is the config file section holding user-defined themes.
+const themesSetting = "themes"
+
+// theme is the set of colors used by the table, the TUI and diff output.
+// Colors are anything lipgloss accepts: ANSI numbers such as "99" or hex
+// such as "#62D9F5". An empty color leaves the terminal's own.
+type theme struct {
+	Text       lipgloss.Color `yaml:"text"`
+	Bright     lipgloss.Color `yaml:"bright"`
+	Background lipgloss.Color `yaml:"background"`
+	Header     lipgloss.Color `yaml:"header"`
+	Accent     lipgloss.Color `yaml:"accent"`
+	StartedFg  lipgloss.Color `yaml:"started_fg"`
+	StartedBg  lipgloss.Color `yaml:"started_bg"`
+	NextFg     lipgloss.Color `yaml:"next_fg"`
+	NextBg     lipgloss.Color `yaml:"next_bg"`
+	Conflict   lipgloss.Color `yaml:"conflict"`
+	Past       lipgloss.Color `yaml:"past"`
+	Upcoming   lipgloss.Color `yaml:"upcoming"`
+	Ongoing    lipgloss.Color `yaml:"ongoing"`
+	Today      lipgloss.Color `yaml:"today"`
+	Muted      lipgloss.Color `yaml:"muted"`
+	SelectedFg lipgloss.Color `yaml:"selected_fg"`
+	SelectedBg lipgloss.Color `yaml:"selected_bg"`
+	Error      lipgloss.Color `yaml:"error"`
+	Added      lipgloss.Color `yaml:"added"`
+	Changed    lipgloss.Color `yaml:"changed"`
+	// Events colors overlapping events side by side in the timeline.
+	Events []lipgloss.Color `yaml:"events"`
+}
+
+// themes are the built-in themes selectable with --theme.
+var themes = map[string]theme{
+	"dark": {
+		Text:       "7",
+		Bright:     "15",
+		Background: "0",
+		Header:     "#FAFAFA",
+		Accent:     "99",
+		StartedFg:  "#FFFFFF",
+		StartedBg:  "#1F4E79",
+		NextFg:     "#000000",
+		NextBg:     "#00FF00",
+		Conflict:   "#FFD700",
+		Past:       "9",
+		Upcoming:   "10",
+		Ongoing:    "2",
+		Today:      "#62D9F5",
+		Muted:      "8",
+		SelectedFg: "0",
+		SelectedBg: "15",
+		Error:      "9",
+		Added:      "10",
+		Changed:    "11",
+		Events:     []lipgloss.Color{"#3B4A9C", "#2F7D4F", "#8A4F9E", "#9C6B26"},
+	},
+	"light": {
+		Text:       "#303030",
+		Bright:     "#000000",
+		Background: "#FFFFFF",
+		Header:     "#000000",
+		Accent:     "#5A3FC0",
+		StartedFg:  "#000000",
+		StartedBg:  "#BFE3FF",
+		NextFg:     "#000000",
+		NextBg:     "#B8F0B8",
+		Conflict:   "#B35C00",
+		Past:       "#A0A0A0",
+		Upcoming:   "#1C7C1C",
+		Ongoing:    "#0064B4",
+		Today:      "#0064B4",
+		Muted:      "#909090",
+		SelectedFg: "#FFFFFF",
+		SelectedBg: "#303030",
+		Error:      "#C00000",
+		Added:      "#1C7C1C",
+		Changed:    "#B35C00",
+		Events:     []lipgloss.Color{"#C9D3FF", "#C4EBD2", "#EBCFF3", "#F6DEBB"},
+	},
+	"solarized": {
+		Text:       "#839496",
+		Bright:     "#FDF6E3",
+		Background: "#002B36",
+		Header:     "#EEE8D5",
+		Accent:     "#6C71C4",
+		StartedFg:  "#FDF6E3",
+		StartedBg:  "#268BD2",
+		NextFg:     "#002B36",
+		NextBg:     "#859900",
+		Conflict:   "#B58900",
+		Past:       "#DC322F",
+		Upcoming:   "#859900",
+		Ongoing:    "#2AA198",
+		Today:      "#2AA198",
+		Muted:      "#586E75",
+		SelectedFg: "#002B36",
+		SelectedBg: "#EEE8D5",
+		Error:      "#DC322F",
+		Added:      "#859900",
+		Changed:    "#B58900",
+		Events:     []lipgloss.Color{"#073642", "#264B3A", "#3B2E58", "#4D3A12"},
+	},
+	// no-color keeps bold, reverse and strikethrough but leaves every color
+	// to the terminal.
+	"no-color": {},
+}
+
+// colors is the theme in use, set from --theme by applyTheme.
+var colors = themes["dark"]
+
+// themeNames lists the themes available with s, built-in ones included.
+func themeNames(s settings) []string {
+	var names []string
+	for name := range themes {
+		names = append(names, name)
+	}
+	if user, err := userThemes(s); err == nil {
+		for name := range user {
+			if _, ok := themes[name]; !ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// userThemes decodes the themes section of the config file, e.g.
+//
+//	themes:
+//	  mine:
+//	    base: solarized
+//	    started_bg: "#005F87"
+//
+// Each theme starts from its base, dark unless given, and overrides the
+// colors it names.
+func userThemes(s settings) (map[string]theme, error) {
+	if s[themesSetting] == nil {
+		return nil, nil
+	}
+	// Round-trip the section to get at it with concrete types; nested
+	// mappings decode as settings.
+	b, err := yaml.Marshal(s[themesSetting])
+	if err != nil {
+		return nil, err
+	}
+	var section map[string]map[string]any
+	if err := yaml.Unmarshal(b, &section); err != nil {
+		return nil, fmt.Errorf("%s must map theme names to colors: %w", themesSetting, err)
+	}
+	user := map[string]theme{}
+	for name, fields := range section {
+		base := "dark"
+		if b, ok := fields["base"]; ok {
+			base = fmt.Sprint(b)
+			delete(fields, "base")
+		}
+		t, ok := themes[base]
+		if !ok {
+			return nil, fmt.Errorf("theme %q: unknown base %q", name, base)
+		}
+		b, err := yaml.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(&t); err != nil {
+			return nil, fmt.Errorf("theme %q: %w", name, err)
+		}
+		user[name] = t
+	}
+	return user, nil
+}
+
+// applyTheme selects the theme called name, looking in the config file's
+// themes before the built-in ones, and restyles the table and diff output.
+func applyTheme(name string, s settings) error {
+	user, err := userThemes(s)
+	if err != nil {
+		return err
+	}
+	t, ok := user[name]
+	if !ok {
+		t, ok = themes[name]
+	}
+	if !ok {
+		return fmt.Errorf("unknown theme %q; use one of %s", name, strings.Join(themeNames(s), ", "))
+	}
+	colors = t
+
+	HeaderStyle = lipgloss.NewStyle().Foreground(t.Header).Background(t.Background)
+	NormalStyle = lipgloss.NewStyle().Foreground(t.Text).Background(t.Background)
+	StartedRowStyle = lipgloss.NewStyle().Bold(true).Foreground(t.StartedFg).Background(t.StartedBg)
+	NextRowStyle = lipgloss.NewStyle().Bold(true).Foreground(t.NextFg).Background(t.NextBg)
+	CaptionStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Accent)
+	ConflictRowStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Conflict).Background(t.Background)
+	DimRowStyle = NormalStyle.Faint(true)
+	if t.StartedBg == "" {
+		StartedRowStyle = StartedRowStyle.Reverse(true)
+	}
+	if t.NextBg == "" {
+		NextRowStyle = NextRowStyle.Underline(true)
+	}
+
+	addedStyle = lipgloss.NewStyle().Foreground(t.Added)
+	removedStyle = lipgloss.NewStyle().Foreground(t.Error).Strikethrough(true)
+	movedStyle = lipgloss.NewStyle().Foreground(t.Changed)
+	return nil
+}
+
+// eventColor returns the timeline color of the i-th overlapping column.
+func (t theme) eventColor(i int) lipgloss.Color {
+	if len(t.Events) == 0 {
+		return ""
+	}
+	return t.Events[i%len(t.Events)]
+}
+
+// selected styles the highlighted row or cell, reversing it when the theme
+// has no selection colors.
+func (t theme) selected(s lipgloss.Style) lipgloss.Style {
+	if t.SelectedBg == "" {
+		return s.Reverse(true)
+	}
+	return s.Foreground(t.SelectedFg).Background(t.SelectedBg)
+}
+
+// plainOutput reports whether output should carry no ANSI styling at all:
+// with --no-color, $NO_COLOR, or when stdout is piped or redirected.
+func plainOutput() bool {
+	return *noColor || os.Getenv(noColorEnv) != "" || !isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// applyPlainOutput strips styling from everything lipgloss renders when
+// plainOutput asks for it.
+func applyPlainOutput() {
+	if plainOutput() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// applyPlainTUI is applyPlainOutput for the TUI, which keeps bold and
+// reverse video but drops the colors: without any styling its selection
+// would be invisible.
+func applyPlainTUI() {
+	if plainOutput() {
+		colors = themes["no-color"]
+		lipgloss.SetColorProfile(termenv.ANSI)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The timesheet command's flags.
+var (
+	timesheetMonth   string
+	timesheetGroupBy string
+)
+
+// timesheetGroupings name what each meeting's time is booked to. A meeting
+// with several guests counts fully towards each of them.
+var timesheetGroupings = map[string]func(item *calendar.Event) []string{
+	"title": func(item *calendar.Event) []string {
+		return []string{item.Summary}
+	},
+	"attendee": func(item *calendar.Event) []string {
+		var names []string
+		for _, a := range item.Attendees {
+			if a.Self || a.Resource {
+				continue
+			}
+			if a.DisplayName != "" {
+				names = append(names, a.DisplayName)
+			} else {
+				names = append(names, a.Email)
+			}
+		}
+		if len(names) == 0 {
+			return []string{"(no guests)"}
+		}
+		return names
+	},
+	"color": func(item *calendar.Event) []string {
+		if name, ok := eventColorNames[item.ColorId]; ok {
+			return []string{name}
+		}
+		return []string{"(calendar color)"}
+	},
+	"calendar": func(item *calendar.Event) []string {
+		return []string{calendarName(eventCalendar(item))}
+	},
+}
+
+func validGroupBy(by string) error {
+	if _, ok := timesheetGroupings[by]; !ok {
+		return fmt.Errorf("unknown grouping %q; use attendee, title, color or calendar", by)
+	}
+	return nil
+}
+
+// timesheet totals the time of the meetings between from and to per
+// grouping, the most time first.
+func timesheet(items []*calendar.Event, from, to time.Time, by string) []statsGroup {
+	groups := map[string]*statsGroup{}
+	for _, item := range countedMeetings(items, from, to) {
+		start, end, _ := eventTimes(item)
+		hours := minTime(end, to).Sub(maxTime(start, from)).Hours()
+		for _, name := range timesheetGroupings[by](item) {
+			g, ok := groups[name]
+			if !ok {
+				g = &statsGroup{Name: name}
+				groups[name] = g
+			}
+			g.Meetings++
+			g.Hours += hours
+		}
+	}
+	return sortedGroups(groups)
+}
+
+// writeTimesheet prints the totals as a table, or as --output csv, tsv or
+// json. Delimited hours are decimal, for spreadsheets.
+func writeTimesheet(w io.Writer, groups []statsGroup, by string) error {
+	switch *outputFormat {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(groups)
+	case "csv", "tsv":
+		records := [][]string{}
+		if !*noHeader {
+			records = append(records, []string{by, "meetings", "hours"})
+		}
+		for _, g := range groups {
+			records = append(records, []string{g.Name, strconv.Itoa(g.Meetings), strconv.FormatFloat(g.Hours, 'f', 2, 64)})
+		}
+		comma := ','
+		if *outputFormat == "tsv" {
+			comma = '\t'
+		}
+		return writeRecords(w, records, comma)
+	}
+	total := 0.0
+	for _, g := range groups {
+		total += g.Hours
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !*noHeader {
+		fmt.Fprintf(tw, "%s\tMEETINGS\tTIME\tSHARE\n", strings.ToUpper(by))
+	}
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.0f%%\n", g.Name, g.Meetings, hoursText(g.Hours), 100*g.Hours/total)
+	}
+	return tw.Flush()
+}
+
+// timesheetWindow resolves --month, or --from and --to when given.
+func timesheetWindow(now time.Time) (time.Time, time.Time, error) {
+	if *fromFlag != "" || *toFlag != "" {
+		return freebusyWindow(now)
+	}
+	if timesheetMonth == "" {
+		first := startOfDay(now).AddDate(0, 0, 1-now.Day())
+		return first, first.AddDate(0, 1, 0), nil
+	}
+	first, err := time.ParseInLocation("2006-01", timesheetMonth, now.Location())
+	if err != nil {
+		return first, first, fmt.Errorf("--month %q must look like 2024-05", timesheetMonth)
+	}
+	return first, first.AddDate(0, 1, 0), nil
+}
+
+// runTimesheet fetches the window's events and prints the totals.
+func runTimesheet(ctx context.Context, srv *calendar.Service, w io.Writer, now time.Time) error {
+	from, to, err := timesheetWindow(now)
+	if err != nil {
+		return err
+	}
+	events, err := fetchEvents(ctx, srv, from, to)
+	if err != nil {
+		return err
+	}
+	warnStale(os.Stderr)
+	groups := timesheet(events.Items, from, to, timesheetGroupBy)
+	if len(groups) == 0 && *outputFormat == "table" {
+		fmt.Fprintln(w, "No meetings found.")
+		return nil
+	}
+	return writeTimesheet(w, groups, timesheetGroupBy)
+}
+
+var timesheetCmd = &cobra.Command{
+	Use:   "timesheet",
+	Short: "Total the time spent in meetings per guest, title, color or calendar",
+	Long: "Add up the time of the meetings in --month, this month unless given, or from --from to --to, grouped by " +
+		"--group-by: each guest, title, event color or calendar. A meeting with several guests counts towards each " +
+		"of them.\n\n" +
+		"Meetings marked free and declined invitations do not count. --output csv or tsv writes decimal hours for " +
+		"spreadsheets, --output json the totals as JSON.",
+	Example: `  go-gcal-cli timesheet --month 2024-05 --group-by attendee
+  go-gcal-cli timesheet --group-by color --output csv > hours.csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validGroupBy(timesheetGroupBy); err != nil {
+			usageFatalf("Invalid --group-by: %v", err)
+		}
+		srv := newService()
+		ctx, cancel := requestContext()
+		defer cancel()
+		if err := runTimesheet(ctx, srv, os.Stdout, time.Now()); err != nil {
+			fatal("Unable to build the timesheet", err)
+		}
+	},
+}
+
+func init() {
+	f := timesheetCmd.Flags()
+	f.StringVar(&timesheetMonth, "month", "", "month to cover, as 2024-05 (default this month)")
+	f.StringVar(&timesheetGroupBy, "group-by", "title", "what to total by: attendee, title, color or calendar")
+	rootCmd.AddCommand(timesheetCmd)
+}
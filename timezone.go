@@ -0,0 +1,77 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// zones caches loaded time zones by IANA name; nil marks unknown names.
+var zones sync.Map
+
+// loadZone loads the IANA time zone name once, returning nil for "" and
+// names the system does not know.
+func loadZone(name string) *time.Location {
+	if name == "" {
+		return nil
+	}
+	if loc, ok := zones.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = nil
+	}
+	zones.Store(name, loc)
+	return loc
+}
+
+// parseDateTime parses the DateTime of an event start or end in the local
+// time zone. A DateTime without an offset is read in the zone it names.
+func parseDateTime(dt *calendar.EventDateTime) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, dt.DateTime); err == nil {
+		return t.In(time.Local), true
+	}
+	loc := loadZone(dt.TimeZone)
+	if loc == nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", dt.DateTime, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.In(time.Local), true
+}
+
+// eventZone is the time zone item was scheduled in: its own, or else its
+// calendar's. It is nil when neither is known.
+func eventZone(item *calendar.Event) *time.Location {
+	if item.Start != nil {
+		if loc := loadZone(item.Start.TimeZone); loc != nil {
+			return loc
+		}
+	}
+	if entry := calendarEntry(eventCalendar(item)); entry != nil {
+		return loadZone(entry.TimeZone)
+	}
+	return nil
+}
+
+// dualTime returns " (09:00 EDT)", t in the zone item was scheduled in,
+// with --dual-time set and that zone's clock showing a different time.
+func dualTime(item *calendar.Event, t time.Time) string {
+	if !*dualTimeFlag {
+		return ""
+	}
+	loc := eventZone(item)
+	if loc == nil {
+		return ""
+	}
+	orig := t.In(loc)
+	_, origOffset := orig.Zone()
+	if _, offset := t.Zone(); offset == origOffset {
+		return ""
+	}
+	return " (" + orig.Format("15:04 MST") + ")"
+}
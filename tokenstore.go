@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keyringService     = "go-gcal-cli"
+	encryptedTokenBase = "token"
+	passphraseEnv      = "GCAL_TOKEN_PASSPHRASE"
+)
+
+// errNoToken is returned by a tokenStore that holds no token yet.
+var errNoToken = errors.New("no saved token")
+
+// tokenStore persists the OAuth token outside of plaintext files.
+type tokenStore interface {
+	Load() (*storedToken, error)
+	Save(tok *storedToken) error
+	Delete() error
+	String() string
+}
+
+// keyringStore keeps the token in the OS keychain: macOS Keychain, the
+// Secret Service on Linux or the Windows Credential Manager.
+type keyringStore struct {
+	user string
+}
+
+func (s keyringStore) Load() (*storedToken, error) {
+	secret, err := keyring.Get(keyringService, s.user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, errNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	tok := &storedToken{}
+	if err := json.Unmarshal([]byte(secret), tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+func (s keyringStore) Save(tok *storedToken) error {
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(keyringService, s.user, string(b))
+}
+
+func (s keyringStore) Delete() error {
+	err := keyring.Delete(keyringService, s.user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s keyringStore) String() string {
+	return "OS keychain"
+}
+
+// encryptedFileStore keeps the token in a file sealed with AES-GCM under a
+// key derived from a passphrase.
+type encryptedFileStore struct {
+	path       string
+	passphrase string
+}
+
+// sealedToken is the on-disk format of encryptedFileStore.
+type sealedToken struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (s encryptedFileStore) aead(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s encryptedFileStore) Load() (*storedToken, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	sealed := &sealedToken{}
+	if err := json.Unmarshal(b, sealed); err != nil {
+		return nil, err
+	}
+	aead, err := s.aead(sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt %s, check %s: %w", s.path, passphraseEnv, err)
+	}
+	tok := &storedToken{}
+	if err := json.Unmarshal(plain, tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+func (s encryptedFileStore) Save(tok *storedToken) error {
+	plain, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	sealed := &sealedToken{Salt: make([]byte, 16)}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return err
+	}
+	aead, err := s.aead(sealed.Salt)
+	if err != nil {
+		return err
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return err
+	}
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, plain, nil)
+
+	b, err := json.Marshal(sealed)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0600)
+}
+
+func (s encryptedFileStore) Delete() error {
+	err := os.Remove(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s encryptedFileStore) String() string {
+	return "encrypted file " + s.path
+}
+
+// tokenStores are the accepted --token-store values.
+var tokenStores = []string{"auto", "keyring", "file"}
+
+func validTokenStore(store string) error {
+	for _, s := range tokenStores {
+		if s == store {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown token store %q; use one of %s", store, strings.Join(tokenStores, ", "))
+}
+
+// keyringAvailable probes the OS keychain with a lookup.
+func keyringAvailable(user string) bool {
+	_, err := keyring.Get(keyringService, user)
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+// openTokenStore picks the backend selected by --token-store. auto prefers
+// the OS keychain and falls back to the encrypted file.
+func openTokenStore() (tokenStore, error) {
+	user := profileFileName("token", "", activeProfile())
+	if *tokenStoreFlag != "file" {
+		if keyringAvailable(user) {
+			return keyringStore{user: user}, nil
+		}
+		if *tokenStoreFlag == "keyring" {
+			return nil, errors.New("the OS keychain is not available")
+		}
+	}
+	passphrase := os.Getenv(passphraseEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("no OS keychain available; set %s to store the token in an encrypted file", passphraseEnv)
+	}
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, profileFileName(encryptedTokenBase, ".enc", activeProfile()))
+	return encryptedFileStore{path: path, passphrase: passphrase}, nil
+}
+
+// importLegacyToken moves a plaintext token.json into store, so existing
+// users keep their authorization without leaving the file behind.
+func importLegacyToken(store tokenStore) (*storedToken, error) {
+	if activeProfile() != defaultProfile {
+		// token.json predates profiles and belongs to the default one.
+		return nil, errNoToken
+	}
+	path := configPath(tokenFile)
+	tok, err := tokenFromFile(path)
+	if err != nil {
+		return nil, errNoToken
+	}
+	if err := store.Save(tok); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Moved plaintext token %s to the %s\n", path, store)
+	return tok, nil
+}
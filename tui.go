@@ -0,0 +1,631 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"google.golang.org/api/calendar/v3"
+)
+
+// maxTUIRows is how many of a day's events the list shows at once until the
+// terminal's height is known; the rest scroll into view.
+const maxTUIRows = 11
+
+// tuiChromeLines are the lines of the list view around its rows: title,
+// filter, header, scroll markers, status and help.
+const tuiChromeLines = 10
+
+type model struct {
+	events  []*calendar.Event // everything fetched for [from, to)
+	srv     *calendar.Service
+	day     time.Time // the selected day
+	from    time.Time
+	to      time.Time
+	loading bool
+	spinner spinner.Model
+	err     error
+	cursor  int    // index of the selected row in visible()
+	offset  int    // index of the first row of visible() on screen
+	detail  bool   // show the selected event's details instead of the day
+	status  string // one-line feedback such as "Opened ..."
+	view    tuiView
+	width   int // of the terminal, once known
+	height  int
+	every   time.Duration // auto-refresh interval, 0 for none
+	form    *eventForm    // the create or edit form, while open
+	filter  textinput.Model
+	picker  *calendarPicker // the calendar switcher, while open
+}
+
+// matchesFilter reports whether item's summary, location or a guest
+// contains the filter text, ignoring case.
+func (m model) matchesFilter(item *calendar.Event) bool {
+	q := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	if q == "" {
+		return true
+	}
+	fields := []string{item.Summary, item.Location}
+	for _, a := range item.Attendees {
+		fields = append(fields, a.Email, a.DisplayName)
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshMsg asks for the events to be fetched again; clockMsg just redraws
+// so that started and next highlighting follows the clock.
+type (
+	refreshMsg struct{}
+	clockMsg   struct{}
+)
+
+// scheduleRefresh fires the next auto-refresh, if enabled.
+func (m model) scheduleRefresh() tea.Cmd {
+	if m.every <= 0 {
+		return nil
+	}
+	return tea.Tick(m.every, func(time.Time) tea.Msg { return refreshMsg{} })
+}
+
+// tickClock fires at the start of every minute.
+func tickClock() tea.Cmd {
+	return tea.Every(time.Minute, func(time.Time) tea.Msg { return clockMsg{} })
+}
+
+// tuiView is one of the ways the TUI lays out events; v cycles through them.
+type tuiView int
+
+const (
+	listView tuiView = iota
+	timelineView
+	weekView
+	monthView
+	tuiViewCount
+)
+
+// window returns the range of events the view needs around the selected
+// day: the day itself, the Monday-to-Sunday week containing it, or the six
+// weeks of a month grid.
+func (m model) window() (time.Time, time.Time) {
+	switch m.view {
+	case weekView:
+		from := startOfWeek(m.day)
+		return from, from.AddDate(0, 0, 7)
+	case monthView:
+		from := startOfWeek(m.day.AddDate(0, 0, 1-m.day.Day()))
+		return from, from.AddDate(0, 0, 6*7)
+	}
+	return m.day, m.day.AddDate(0, 0, 1)
+}
+
+// startOfWeek returns midnight of the Monday on or before t.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// dayEvents returns the fetched timed events that overlap the day starting
+// at day and match the filter.
+func (m model) dayEvents(day time.Time) []*calendar.Event {
+	var items []*calendar.Event
+	for _, item := range m.events {
+		startTime, endTime, ok := eventTimes(item)
+		if ok && startTime.Before(day.AddDate(0, 0, 1)) && endTime.After(day) && m.matchesFilter(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// visible returns the timed events listed for the selected day.
+func (m model) visible() []*calendar.Event {
+	return m.dayEvents(m.day)
+}
+
+// pageRows is how many rows the list view has room for.
+func (m model) pageRows() int {
+	if m.height == 0 {
+		return maxTUIRows
+	}
+	return max(3, m.height-tuiChromeLines)
+}
+
+// summaryWidth is the width of the list view's summary column: 50, or what
+// the terminal leaves next to the times and the link.
+func (m model) summaryWidth() int {
+	if m.width == 0 {
+		return 50
+	}
+	return max(20, m.width-34)
+}
+
+// scrollOffset returns the first row to show: m.offset, moved just enough
+// to keep the cursor on screen.
+func (m model) scrollOffset() int {
+	return max(0, min(m.offset, m.cursor), m.cursor-m.pageRows()+1)
+}
+
+// selected returns the event under the cursor, or nil for an empty day.
+func (m model) selected() *calendar.Event {
+	items := m.visible()
+	if m.cursor < 0 || m.cursor >= len(items) {
+		return nil
+	}
+	return items[m.cursor]
+}
+
+// moveCursor moves the selection by delta, stopping at either end. The
+// month view has no event selection and moves by weeks instead.
+func (m model) moveCursor(delta int) (tea.Model, tea.Cmd) {
+	if m.view == monthView {
+		return m.showDay(m.day.AddDate(0, 0, 7*delta))
+	}
+	m.cursor = max(0, min(m.cursor+delta, len(m.visible())-1))
+	m.offset = m.scrollOffset()
+	m.status = ""
+	return m, nil
+}
+
+// openSelected opens the selected event's conference link in the browser.
+func (m model) openSelected() (tea.Model, tea.Cmd) {
+	item := m.selected()
+	if item == nil {
+		return m, nil
+	}
+	link := eventLink(item, *linkSource)
+	switch {
+	case link == "":
+		m.status = "No link for " + item.Summary
+	case openBrowser(link) != nil:
+		m.status = "Unable to open " + link
+	default:
+		m.status = "Opened " + link
+	}
+	return m, nil
+}
+
+// copySelected copies the selected event's meeting link, or its Google
+// Calendar page when it has none, to the clipboard.
+func (m model) copySelected() (tea.Model, tea.Cmd) {
+	item := m.selected()
+	if item == nil {
+		return m, nil
+	}
+	link := shareLink(item)
+	switch {
+	case link == "":
+		m.status = "No link for " + item.Summary
+	case copyToClipboard(link) != nil:
+		m.status = "Unable to copy " + link
+	default:
+		m.status = "Copied " + link
+	}
+	return m, nil
+}
+
+// rangeEventsMsg carries the result of fetching the events of a range.
+type rangeEventsMsg struct {
+	from, to time.Time
+	events   []*calendar.Event
+	err      error
+}
+
+func fetchRange(srv *calendar.Service, from, to time.Time) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := requestContext()
+		defer cancel()
+		events, err := fetchEvents(ctx, srv, from, to)
+		if err != nil {
+			return rangeEventsMsg{from: from, to: to, err: err}
+		}
+		return rangeEventsMsg{from: from, to: to, events: filterEvents(events.Items)}
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, fetchRange(m.srv, m.from, m.to), m.scheduleRefresh(), tickClock())
+}
+
+// showDay selects day and fetches the events around it, unless the view
+// already has them.
+func (m model) showDay(day time.Time) (tea.Model, tea.Cmd) {
+	if !startOfDay(day).Equal(m.day) {
+		m.cursor = 0
+	}
+	m.day = startOfDay(day)
+	m.status = ""
+	if from, to := m.window(); !m.loading && !m.from.IsZero() && !from.Before(m.from) && !to.After(m.to) {
+		return m, nil
+	}
+	return m.refresh()
+}
+
+// useCalendars switches to the calendars ids, saving them as the default in
+// the config file, and fetches their events.
+func (m model) useCalendars(ids []string) (tea.Model, tea.Cmd) {
+	calendarFlags = ids
+	*allCalendars = false
+	s, err := loadSettings()
+	if err == nil {
+		s["calendar"] = ids
+		err = saveSettings(s)
+	}
+	if err != nil {
+		m.status = "Unable to save calendars: " + err.Error()
+	} else {
+		m.status = fmt.Sprintf("Showing %d calendars", len(ids))
+	}
+	return m.refresh()
+}
+
+// refresh fetches the events of the view's window again.
+func (m model) refresh() (tea.Model, tea.Cmd) {
+	m.from, m.to = m.window()
+	m.loading = true
+	return m, tea.Batch(m.spinner.Tick, fetchRange(m.srv, m.from, m.to))
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.form != nil && msg.Type != tea.KeyCtrlC {
+			done, cmd := m.form.update(msg, m.srv)
+			if done && cmd == nil {
+				m.form = nil
+			}
+			return m, cmd
+		}
+		if m.picker != nil && msg.Type != tea.KeyCtrlC {
+			done, apply := m.picker.update(msg)
+			if !done {
+				return m, nil
+			}
+			picker := m.picker
+			m.picker = nil
+			if !apply {
+				return m, nil
+			}
+			return m.useCalendars(picker.ids())
+		}
+		if m.filter.Focused() && msg.Type != tea.KeyCtrlC {
+			switch msg.Type {
+			case tea.KeyEsc:
+				m.filter.SetValue("")
+				m.filter.Blur()
+			case tea.KeyEnter:
+				m.filter.Blur()
+			default:
+				var cmd tea.Cmd
+				m.filter, cmd = m.filter.Update(msg)
+				m.cursor = max(0, min(m.cursor, len(m.visible())-1))
+				return m, cmd
+			}
+			return m, nil
+		}
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			return m, tea.Quit
+		case tea.KeyLeft:
+			return m.showDay(m.day.AddDate(0, 0, -1))
+		case tea.KeyRight:
+			return m.showDay(m.day.AddDate(0, 0, 1))
+		case tea.KeyUp:
+			return m.moveCursor(-1)
+		case tea.KeyDown:
+			return m.moveCursor(1)
+		case tea.KeyPgUp:
+			return m.moveCursor(-m.pageRows())
+		case tea.KeyPgDown:
+			return m.moveCursor(m.pageRows())
+		case tea.KeyEnter:
+			if m.view == monthView {
+				// Drill into the selected day's agenda.
+				m.view = listView
+				return m.showDay(m.day)
+			}
+			return m.openSelected()
+		case tea.KeyEsc, tea.KeyBackspace:
+			if !m.detail && msg.Type == tea.KeyEsc {
+				m.filter.SetValue("")
+			}
+			m.detail = false
+			return m, nil
+		}
+		switch msg.String() {
+		case "q":
+			return m, tea.Quit
+		case "h":
+			return m.showDay(m.day.AddDate(0, 0, -1))
+		case "l":
+			return m.showDay(m.day.AddDate(0, 0, 1))
+		case "k":
+			return m.moveCursor(-1)
+		case "j":
+			return m.moveCursor(1)
+		case "[":
+			return m.showDay(m.day.AddDate(0, 0, -7))
+		case "]":
+			return m.showDay(m.day.AddDate(0, 0, 7))
+		case "t":
+			return m.showDay(time.Now())
+		case "r":
+			return m.refresh()
+		case "d":
+			m.detail = !m.detail
+			return m, nil
+		case "/":
+			m.cursor = 0
+			return m, m.filter.Focus()
+		case "c":
+			m.picker = newCalendarPicker()
+			return m, fetchCalendarList(m.srv)
+		case "n":
+			m.form = newEventForm(m.day, nil)
+			return m, nil
+		case "e":
+			if item := m.selected(); item != nil {
+				m.form = newEventForm(m.day, item)
+			}
+			return m, nil
+		case "y":
+			return m.copySelected()
+		case "v":
+			m.view = (m.view + 1) % tuiViewCount
+			return m.showDay(m.day)
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.offset = m.scrollOffset()
+	case calendarListMsg:
+		if m.picker != nil {
+			m.picker.setItems(msg.items)
+			m.picker.err = msg.err
+		}
+		return m, nil
+	case formSavedMsg:
+		if msg.err != nil {
+			if m.form != nil {
+				m.form.err = msg.err.Error()
+			}
+			return m, nil
+		}
+		m.form = nil
+		m.status = "Saved " + msg.item.Summary
+		return m.refresh()
+	case refreshMsg:
+		if m.loading {
+			return m, m.scheduleRefresh()
+		}
+		next, cmd := m.refresh()
+		return next, tea.Batch(cmd, m.scheduleRefresh())
+	case clockMsg:
+		return m, tickClock()
+	case rangeEventsMsg:
+		// Ignore responses for ranges the user has already navigated away from.
+		if !msg.from.Equal(m.from) || !msg.to.Equal(m.to) {
+			return m, nil
+		}
+		m.loading = false
+		m.events, m.err = msg.events, msg.err
+		m.cursor = max(0, min(m.cursor, len(m.visible())-1))
+	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	var output string
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(colors.Bright).Background(colors.Accent).Padding(0, 1).Render
+	header := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(colors.Bright).Background(colors.Background).Render
+	oldStyle := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(colors.Past).Background(colors.Background).Render
+	newStyle := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(colors.Upcoming).Background(colors.Background).Render
+	currentStyle := lipgloss.NewStyle().Align(lipgloss.Center).Foreground(colors.Ongoing).Background(colors.Background).Render
+	selectedStyle := colors.selected(lipgloss.NewStyle().Bold(true)).Render
+
+	switch m.view {
+	case weekView:
+		from, to := m.window()
+		output += title(from.Format("Jan 2") + " – " + to.AddDate(0, 0, -1).Format("Jan 2 2006"))
+	case monthView:
+		output += title(m.day.Format("January 2006"))
+	default:
+		output += title(m.day.Format("Monday, Jan 2 2006"))
+	}
+	if m.loading {
+		output += " " + m.spinner.View()
+	}
+	output += "\n"
+
+	if m.form != nil {
+		return output + "\n" + m.form.view()
+	}
+	if m.picker != nil {
+		return output + "\n" + m.picker.view()
+	}
+	if m.filter.Focused() || m.filter.Value() != "" {
+		output += m.filter.View() + "\n"
+	}
+
+	if m.err != nil {
+		return output + fmt.Sprintf("Unable to retrieve events: %v\n", m.err)
+	}
+
+	if item := m.selected(); m.detail && item != nil {
+		output += "\n" + eventDetail(item)
+		if m.status != "" {
+			output += "\n" + m.status + "\n"
+		}
+		return output + "\nesc/d back • enter open link • y copy link • q quit\n"
+	}
+
+	help := "\n↑/k ↓/j select • pgup/pgdn page • enter open link • y copy link • d details • / filter • c calendars • n new • e edit • v view • ←/h →/l day • [/] week • t today • r refresh • q quit\n"
+	if m.view != listView {
+		width := m.width
+		if width == 0 {
+			width = 80
+		}
+		switch m.view {
+		case timelineView:
+			output += m.timelineView(width)
+		case weekView:
+			output += m.weekView(width)
+		case monthView:
+			output += m.monthView(width)
+			help = "\n←/h →/l day • ↑/k ↓/j week • enter open day • v view • t today • r refresh • q quit\n"
+		}
+		if m.status != "" {
+			output += "\n" + m.status + "\n"
+		}
+		return output + help
+	}
+
+	sumWidth := m.summaryWidth()
+	output += header(fmt.Sprintf("%s %-5s-%-5s %-20s\n", runewidth.FillRight("Summary", sumWidth), "Start", "End", "Hangout Link"))
+
+	now := time.Now()
+	items := m.visible()
+	offset := m.scrollOffset()
+	end := min(len(items), offset+m.pageRows())
+	if offset > 0 {
+		output += oldStyle(fmt.Sprintf("↑ %d more\n", offset))
+	}
+	for i := offset; i < end; i++ {
+		event := items[i]
+		startTime, endTime, _ := eventTimes(event)
+
+		style := newStyle
+		if !endTime.After(now) {
+			style = oldStyle
+		} else if isOngoing(now, startTime, endTime) {
+			style = currentStyle
+		}
+		if i == m.cursor {
+			style = selectedStyle
+		}
+
+		summary := runewidth.FillRight(truncate(event.Summary, sumWidth-3), sumWidth)
+		output += style(fmt.Sprintf("%s %-5s-%-5s %-20s\n", summary, startTime.Format("15:04"), endTime.Format("15:04"), eventLink(event, *linkSource)))
+
+		//		output += style.Render(fmt.Sprintf("%-30s %-20s %-20s %-50s\n", event.Summary, startTime.Format("15:04"), endTime.Format("15:04"), event.HangoutLink))
+	}
+	if end < len(items) {
+		output += oldStyle(fmt.Sprintf("↓ %d more\n", len(items)-end))
+	}
+
+	if m.status != "" {
+		output += "\n" + m.status + "\n"
+	}
+
+	output += help
+
+	return output
+}
+
+// eventDetail describes an event for the detail view: time, place,
+// organizer, every way to join, guests with their answers, attachments and
+// the description.
+func eventDetail(item *calendar.Event) string {
+	label := lipgloss.NewStyle().Bold(true).Foreground(colors.Accent).Width(11).Render
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(item.Summary), "", label("When") + formatEventTime(item)}
+	if item.Location != "" {
+		lines = append(lines, label("Location")+item.Location)
+	}
+	if item.Organizer != nil {
+		organizer := item.Organizer.Email
+		if item.Organizer.DisplayName != "" {
+			organizer = item.Organizer.DisplayName + " <" + organizer + ">"
+		}
+		lines = append(lines, label("Organizer")+organizer)
+	}
+
+	var joins []string
+	if item.ConferenceData != nil {
+		for _, ep := range item.ConferenceData.EntryPoints {
+			join := ep.EntryPointType + ": " + ep.Uri
+			if ep.Pin != "" {
+				join += " (PIN " + ep.Pin + ")"
+			}
+			joins = append(joins, join)
+		}
+	}
+	if len(joins) == 0 {
+		if link := eventLink(item, *linkSource); link != "" {
+			joins = append(joins, link)
+		}
+	}
+	for i, join := range joins {
+		name := ""
+		if i == 0 {
+			name = "Join"
+		}
+		lines = append(lines, label(name)+join)
+	}
+
+	for i, a := range item.Attendees {
+		name := ""
+		if i == 0 {
+			name = fmt.Sprintf("Guests (%d)", len(item.Attendees))
+		}
+		guest := a.Email
+		if a.DisplayName != "" {
+			guest = a.DisplayName + " <" + a.Email + ">"
+		}
+		if a.Optional {
+			guest += " (optional)"
+		}
+		glyph, ok := responseGlyphs[a.ResponseStatus]
+		if !ok {
+			glyph = responseGlyphs["needsAction"]
+		}
+		lines = append(lines, label(name)+glyph+" "+guest)
+	}
+
+	for i, att := range item.Attachments {
+		name := ""
+		if i == 0 {
+			name = "Files"
+		}
+		lines = append(lines, label(name)+att.Title+" "+att.FileUrl)
+	}
+
+	if item.Description != "" {
+		lines = append(lines, "", descriptionText(item.Description))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func runBubbleTea(srv *calendar.Service, every time.Duration) {
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "filter by title, guest or place"
+	m := model{
+		srv:     srv,
+		every:   every,
+		filter:  filter,
+		day:     startOfDay(time.Now()),
+		loading: true,
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot)),
+	}
+	m.from, m.to = m.window()
+	p := tea.NewProgram(m)
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("Error running program: %v\n", err)
+	}
+}
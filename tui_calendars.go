@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/api/calendar/v3"
+)
+
+// calendarPicker toggles which calendars the TUI merges.
+type calendarPicker struct {
+	items    []*calendar.CalendarListEntry
+	selected map[string]bool
+	cursor   int
+	err      error
+}
+
+// calendarListMsg carries the user's calendar list.
+type calendarListMsg struct {
+	items []*calendar.CalendarListEntry
+	err   error
+}
+
+func fetchCalendarList(srv *calendar.Service) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := requestContext()
+		defer cancel()
+		items, err := listCalendars(ctx, srv)
+		return calendarListMsg{items: items, err: err}
+	}
+}
+
+// newCalendarPicker starts with the calendars currently shown selected.
+func newCalendarPicker() *calendarPicker {
+	p := &calendarPicker{selected: map[string]bool{}}
+	for _, id := range calendarIDs() {
+		p.selected[id] = true
+	}
+	return p
+}
+
+// setItems fills in the calendar list, resolving the primary alias to the
+// primary calendar's ID.
+func (p *calendarPicker) setItems(items []*calendar.CalendarListEntry) {
+	p.items = items
+	for _, item := range items {
+		if item.Primary && p.selected["primary"] {
+			delete(p.selected, "primary")
+			p.selected[item.Id] = true
+		}
+	}
+}
+
+// ids returns the selected calendars in list order.
+func (p *calendarPicker) ids() []string {
+	var ids []string
+	for _, item := range p.items {
+		if p.selected[item.Id] {
+			ids = append(ids, item.Id)
+		}
+	}
+	return ids
+}
+
+// update handles a key press. It reports whether the picker is done and,
+// if so, whether the selection should be applied.
+func (p *calendarPicker) update(msg tea.KeyMsg) (done, apply bool) {
+	switch msg.String() {
+	case "esc", "q":
+		return true, false
+	case "enter":
+		return true, len(p.ids()) > 0
+	case "up", "k":
+		p.cursor = max(0, p.cursor-1)
+	case "down", "j":
+		p.cursor = max(0, min(len(p.items)-1, p.cursor+1))
+	case " ", "x":
+		if p.cursor < len(p.items) {
+			id := p.items[p.cursor].Id
+			p.selected[id] = !p.selected[id]
+		}
+	}
+	return false, false
+}
+
+func (p *calendarPicker) view() string {
+	if p.err != nil {
+		return fmt.Sprintf("Unable to list calendars: %v\n\nesc back\n", p.err)
+	}
+	if p.items == nil {
+		return "Loading calendars...\n"
+	}
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("Calendars"), ""}
+	for i, item := range p.items {
+		box := "[ ]"
+		if p.selected[item.Id] {
+			box = "[x]"
+		}
+		color := item.BackgroundColor
+		if color == "" {
+			color = "#FFFFFF"
+		}
+		line := box + " " + lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("■") + " " + item.Summary
+		if i == p.cursor {
+			line = lipgloss.NewStyle().Bold(true).Reverse(true).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "↑/k ↓/j move • space toggle • enter apply and save • esc cancel")
+	return strings.Join(lines, "\n") + "\n"
+}
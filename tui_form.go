@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-gcal-cli/events"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/api/calendar/v3"
+)
+
+// The text fields of eventForm, in tab order. The Meet toggle follows them.
+const (
+	formTitle = iota
+	formDate
+	formTime
+	formDuration
+	formAttendees
+	formFields
+)
+
+var formLabels = [formFields]string{"Title", "Date", "Time", "Duration", "Attendees"}
+
+// eventForm creates a new event, or edits one when editing is set.
+type eventForm struct {
+	editing *calendar.Event
+	inputs  [formFields]textinput.Model
+	meet    bool
+	focus   int // formFields when the Meet toggle has the focus
+	err     string
+}
+
+// formSavedMsg reports the result of submitting an eventForm.
+type formSavedMsg struct {
+	item *calendar.Event
+	err  error
+}
+
+// newEventForm starts a form on day, filled in from item when editing.
+func newEventForm(day time.Time, item *calendar.Event) *eventForm {
+	f := &eventForm{editing: item}
+	for i := range f.inputs {
+		f.inputs[i] = textinput.New()
+		f.inputs[i].Prompt = ""
+		f.inputs[i].Width = 40
+	}
+	f.inputs[formDate].Placeholder = "2024-06-03, tomorrow, friday"
+	f.inputs[formTime].Placeholder = "14:00, 9am"
+	f.inputs[formAttendees].Placeholder = "a@example.com, b@example.com"
+
+	f.inputs[formDate].SetValue(day.Format("2006-01-02"))
+	f.inputs[formDuration].SetValue("30m")
+	if item != nil {
+		f.inputs[formTitle].SetValue(item.Summary)
+		if startTime, endTime, ok := eventTimes(item); ok {
+			f.inputs[formDate].SetValue(startTime.Local().Format("2006-01-02"))
+			f.inputs[formTime].SetValue(startTime.Local().Format("15:04"))
+			f.inputs[formDuration].SetValue(endTime.Sub(startTime).String())
+		}
+		var emails []string
+		for _, a := range item.Attendees {
+			if !a.Self {
+				emails = append(emails, a.Email)
+			}
+		}
+		f.inputs[formAttendees].SetValue(strings.Join(emails, ", "))
+		f.meet = conferenceLink(item) != ""
+	}
+	f.inputs[formTitle].Focus()
+	return f
+}
+
+// setFocus moves the focus to field i, wrapping around.
+func (f *eventForm) setFocus(i int) {
+	f.focus = (i + formFields + 1) % (formFields + 1)
+	for j := range f.inputs {
+		if j == f.focus {
+			f.inputs[j].Focus()
+		} else {
+			f.inputs[j].Blur()
+		}
+	}
+}
+
+// spec reads the fields into an event description.
+func (f *eventForm) spec(now time.Time) (events.Spec, error) {
+	spec := events.Spec{Title: strings.TrimSpace(f.inputs[formTitle].Value()), Meet: f.meet, TimeZone: *tzFlag}
+	day, err := parseDate(f.inputs[formDate].Value(), now)
+	if err != nil {
+		return spec, fmt.Errorf("date: %w", err)
+	}
+	hour, minute, ok := parseClock(strings.ToLower(strings.TrimSpace(f.inputs[formTime].Value())))
+	if !ok {
+		return spec, fmt.Errorf("time: %q is not a time of day", f.inputs[formTime].Value())
+	}
+	spec.Start = atClock(day, hour, minute)
+	if spec.Duration, err = time.ParseDuration(strings.TrimSpace(f.inputs[formDuration].Value())); err != nil {
+		return spec, fmt.Errorf("duration: %w", err)
+	}
+	for _, email := range strings.Split(f.inputs[formAttendees].Value(), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			spec.Attendees = append(spec.Attendees, email)
+		}
+	}
+	return spec, nil
+}
+
+// submit saves the form in the background. Edits keep the existing guests'
+// responses and only ask for a conference when there is none yet.
+func (f *eventForm) submit(srv *calendar.Service) tea.Cmd {
+	spec, err := f.spec(time.Now())
+	if err != nil {
+		f.err = err.Error()
+		return nil
+	}
+	item, err := spec.Event()
+	if err != nil {
+		f.err = err.Error()
+		return nil
+	}
+	editing, meet := f.editing, f.meet
+	return func() tea.Msg {
+		ctx, cancel := requestContext()
+		defer cancel()
+		if editing == nil {
+			saved, err := events.Insert(ctx, srv, calendarIDs()[0], item, callOptions()...)
+			return formSavedMsg{saved, scopeError(err)}
+		}
+
+		existing := map[string]*calendar.EventAttendee{}
+		for _, a := range editing.Attendees {
+			existing[strings.ToLower(a.Email)] = a
+		}
+		attendees := []*calendar.EventAttendee{}
+		for _, a := range editing.Attendees {
+			if a.Self || a.Organizer {
+				attendees = append(attendees, a)
+			}
+		}
+		for _, a := range item.Attendees {
+			if old, ok := existing[strings.ToLower(a.Email)]; ok {
+				a = old
+			}
+			if !a.Self && !a.Organizer {
+				attendees = append(attendees, a)
+			}
+		}
+		patch := &calendar.Event{
+			Summary:         item.Summary,
+			Start:           item.Start,
+			End:             item.End,
+			Attendees:       attendees,
+			ForceSendFields: []string{"Attendees"},
+		}
+		if meet && conferenceLink(editing) == "" {
+			patch.ConferenceData = item.ConferenceData
+		}
+		saved, err := events.Patch(ctx, srv, eventCalendar(editing), editing.Id, patch, true, callOptions()...)
+		return formSavedMsg{saved, scopeError(err)}
+	}
+}
+
+// update handles a key press, reporting whether the form is done: submitted
+// or cancelled.
+func (f *eventForm) update(msg tea.KeyMsg, srv *calendar.Service) (bool, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		return true, nil
+	case tea.KeyTab, tea.KeyDown:
+		f.setFocus(f.focus + 1)
+		return false, nil
+	case tea.KeyShiftTab, tea.KeyUp:
+		f.setFocus(f.focus - 1)
+		return false, nil
+	case tea.KeyCtrlS:
+		cmd := f.submit(srv)
+		return cmd != nil, cmd
+	case tea.KeyEnter:
+		if f.focus < formFields-1 {
+			f.setFocus(f.focus + 1)
+			return false, nil
+		}
+		cmd := f.submit(srv)
+		return cmd != nil, cmd
+	}
+	if f.focus == formFields {
+		if msg.String() == " " {
+			f.meet = !f.meet
+		}
+		return false, nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return false, cmd
+}
+
+func (f *eventForm) view() string {
+	label := lipgloss.NewStyle().Bold(true).Foreground(colors.Accent).Width(11).Render
+	focused := lipgloss.NewStyle().Bold(true).Foreground(colors.Bright).Width(11).Render
+
+	heading := "New event"
+	if f.editing != nil {
+		heading = "Edit " + f.editing.Summary
+	}
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(heading), ""}
+	for i, input := range f.inputs {
+		render := label
+		if i == f.focus {
+			render = focused
+		}
+		lines = append(lines, render(formLabels[i])+input.View())
+	}
+	toggle := "[ ]"
+	if f.meet {
+		toggle = "[x]"
+	}
+	render := label
+	if f.focus == formFields {
+		render = focused
+	}
+	lines = append(lines, render("Meet")+toggle+" add a Google Meet link")
+	if f.err != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(colors.Error).Render(f.err))
+	}
+	lines = append(lines, "", "tab/↑↓ move • space toggle Meet • enter next/save • ctrl+s save • esc cancel")
+	return strings.Join(lines, "\n") + "\n"
+}
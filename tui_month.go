@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// monthView draws a six-week grid around the selected day's month, with a
+// dot per event (and the count once they no longer fit) in each day.
+func (m model) monthView(width int) string {
+	from, _ := m.window()
+	colWidth := max(6, width/7)
+	today := startOfDay(time.Now())
+
+	header := lipgloss.NewStyle().Bold(true).Foreground(colors.Accent).Width(colWidth)
+	cell := lipgloss.NewStyle().Width(colWidth)
+	otherMonth := cell.Foreground(colors.Muted)
+	todayCell := cell.Bold(true).Foreground(colors.Today)
+	selected := colors.selected(cell.Bold(true))
+
+	var b strings.Builder
+	var names []string
+	for d := 0; d < 7; d++ {
+		names = append(names, header.Render(from.AddDate(0, 0, d).Format("Mon")))
+	}
+	b.WriteString(strings.Join(names, "") + "\n")
+
+	for week := 0; week < 6; week++ {
+		var cells []string
+		for d := 0; d < 7; d++ {
+			day := from.AddDate(0, 0, 7*week+d)
+			n := len(m.dayEvents(day))
+			dots := strings.Repeat("•", n)
+			if n > colWidth-4 {
+				dots = fmt.Sprintf("%d", n)
+			}
+			style := cell
+			switch {
+			case day.Equal(m.day):
+				style = selected
+			case day.Equal(today):
+				style = todayCell
+			case day.Month() != m.day.Month():
+				style = otherMonth
+			}
+			cells = append(cells, style.Render(fmt.Sprintf("%2d %s", day.Day(), dots)))
+		}
+		b.WriteString(strings.Join(cells, "") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/api/calendar/v3"
+)
+
+// timelineSlot is the time one timeline row stands for.
+const timelineSlot = 30 * time.Minute
+
+// timelineColumns assigns each event the leftmost column that is free when
+// it starts, so that overlapping events end up side by side. It returns the
+// column of each event and the number of columns.
+func timelineColumns(items []*calendar.Event) ([]int, int) {
+	columns := make([]int, len(items))
+	var ends []time.Time // when each column becomes free
+	for i, item := range items {
+		startTime, endTime, _ := eventTimes(item)
+		col := -1
+		for c, end := range ends {
+			if !end.After(startTime) {
+				col = c
+				break
+			}
+		}
+		if col < 0 {
+			col = len(ends)
+			ends = append(ends, time.Time{})
+		}
+		ends[col] = endTime
+		columns[i] = col
+	}
+	return columns, max(1, len(ends))
+}
+
+// timelineHours returns the hours the timeline covers: working hours,
+// stretched to include every event of the day.
+func timelineHours(day time.Time, items []*calendar.Event) (int, int) {
+	first, last := 8, 18
+	for _, item := range items {
+		startTime, endTime, _ := eventTimes(item)
+		startTime, endTime = startTime.Local(), endTime.Local()
+		if startTime.Before(day) {
+			first = 0
+		} else {
+			first = min(first, startTime.Hour())
+		}
+		if endTime.After(day.AddDate(0, 0, 1)) {
+			last = 24
+		} else {
+			last = max(last, endTime.Hour()+min(1, endTime.Minute()))
+		}
+	}
+	return first, last
+}
+
+// timelineView draws the day's events on an hour-by-hour grid, one row per
+// half hour, with a marker on the row containing now.
+func (m model) timelineView(width int) string {
+	items := m.visible()
+	columns, n := timelineColumns(items)
+	first, last := timelineHours(m.day, items)
+
+	const gutter = 7 // "09:00 " plus the now marker
+	colWidth := max(8, (width-gutter)/n)
+	nowStyle := lipgloss.NewStyle().Foreground(colors.Error).Bold(true)
+	hourStyle := lipgloss.NewStyle().Foreground(colors.Muted)
+	now := time.Now()
+
+	var b strings.Builder
+	for t := m.day.Add(time.Duration(first) * time.Hour); t.Before(m.day.Add(time.Duration(last) * time.Hour)); t = t.Add(timelineSlot) {
+		label := "      "
+		if t.Minute() == 0 {
+			label = t.Format("15:04") + " "
+		}
+		marker := " "
+		if !now.Before(t) && now.Before(t.Add(timelineSlot)) {
+			label, marker = nowStyle.Render(label), nowStyle.Render("▶")
+		} else {
+			label = hourStyle.Render(label)
+		}
+		b.WriteString(label + marker)
+
+		cells := make([]string, n)
+		for c := range cells {
+			cells[c] = strings.Repeat(" ", colWidth)
+		}
+		for i, item := range items {
+			startTime, endTime, _ := eventTimes(item)
+			if !startTime.Before(t.Add(timelineSlot)) || !endTime.After(t) {
+				continue
+			}
+			text := ""
+			if !startTime.Before(t) || t.Equal(m.day.Add(time.Duration(first)*time.Hour)) {
+				// The first row of an event carries its title.
+				text = startTime.Local().Format("15:04") + " " + item.Summary
+			}
+			// MaxWidth cuts titles that do not fit rather than wrapping them.
+			style := lipgloss.NewStyle().MaxWidth(colWidth - 1).
+				Foreground(colors.Bright).Background(colors.eventColor(columns[i]))
+			if i == m.cursor {
+				style = colors.selected(style.Bold(true))
+			}
+			cells[columns[i]] = style.Render(fmt.Sprintf(" %-*s", colWidth-2, text)) + " "
+		}
+		b.WriteString(strings.Join(cells, "") + "\n")
+	}
+	if len(items) == 0 {
+		b.WriteString(fmt.Sprintf("%*s\n", gutter+len("No events"), "No events"))
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// weekView draws the selected day's week as seven columns of event titles.
+// Today's column is highlighted, as are the selected day and event.
+func (m model) weekView(width int) string {
+	from, _ := m.window()
+	colWidth := max(10, width/7)
+	now := time.Now()
+	today := startOfDay(now)
+
+	header := lipgloss.NewStyle().Bold(true).Foreground(colors.Bright).Background(colors.Background).Width(colWidth)
+	todayHeader := header.Foreground(colors.Background).Background(colors.Today)
+	selectedHeader := colors.selected(header)
+	cell := lipgloss.NewStyle().Width(colWidth)
+	past := cell.Foreground(colors.Muted)
+	ongoing := cell.Bold(true).Foreground(colors.Today)
+	selected := colors.selected(cell.Bold(true))
+
+	var columns []string
+	for d := 0; d < 7; d++ {
+		day := from.AddDate(0, 0, d)
+		style := header
+		switch {
+		case day.Equal(m.day):
+			style = selectedHeader
+		case day.Equal(today):
+			style = todayHeader
+		}
+		lines := []string{style.Render(day.Format("Mon 2"))}
+		for i, item := range m.dayEvents(day) {
+			startTime, endTime, _ := eventTimes(item)
+			text := startTime.Local().Format("15:04") + " " + item.Summary
+			if startTime.Before(day) {
+				text = "…" + item.Summary
+			}
+			style := cell
+			switch {
+			case day.Equal(m.day) && i == m.cursor:
+				style = selected
+			case isOngoing(now, startTime, endTime):
+				style = ongoing
+			case !endTime.After(now):
+				style = past
+			}
+			lines = append(lines, style.Render(clip(text, colWidth-1)))
+		}
+		columns = append(columns, strings.Join(lines, "\n"))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...) + "\n"
+}
+
+// clip shortens s to n characters, marking the cut with an ellipsis, so that
+// grid cells never wrap.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/api/calendar/v3"
+)
+
+// watcher polls the calendar and notifies of meetings shortly before they
+// start, once each. With openLink set it also joins them.
+type watcher struct {
+	srv      *calendar.Service
+	before   time.Duration
+	interval time.Duration
+	// openLink is how long before a meeting its link is opened, keyed by
+	// calendar ID; the "" key applies to every other calendar.
+	openLink map[string]time.Duration
+	// notified and opened hold the start of every meeting already notified
+	// of or opened, keyed by watchKey.
+	notified map[string]time.Time
+	opened   map[string]time.Time
+	log      *slog.Logger
+
+	// hooks are the commands run on meeting start and end and on new
+	// invitations; see parseHooks. started holds the meetings in progress,
+	// invited the invitations already seen since invitesSince.
+	hooks        map[string][]string
+	started      map[string]*calendar.Event
+	invited      map[string]bool
+	invitesSince time.Time
+
+	// slack, when set, mirrors the meetings in progress in the Slack status.
+	slack *slackStatus
+
+	// reload, when set, is called between checks after requestReload.
+	reload  func(ctx context.Context) error
+	reloads chan struct{}
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	lastErr   error
+	calendars []string
+}
+
+func newWatcher(srv *calendar.Service, before, interval time.Duration) *watcher {
+	return &watcher{
+		srv:      srv,
+		before:   before,
+		interval: interval,
+		openLink: map[string]time.Duration{},
+		notified: map[string]time.Time{},
+		opened:   map[string]time.Time{},
+		log:      slog.Default(),
+		hooks:    map[string][]string{},
+		started:  map[string]*calendar.Event{},
+		invited:  map[string]bool{},
+		reloads:  make(chan struct{}, 1),
+	}
+}
+
+// requestReload asks the run loop to call w.reload before its next check.
+func (w *watcher) requestReload() {
+	select {
+	case w.reloads <- struct{}{}:
+	default:
+	}
+}
+
+// lastResult returns when which calendars were last checked and how that
+// went.
+func (w *watcher) lastResult() (time.Time, []string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastCheck, w.calendars, w.lastErr
+}
+
+// autoOpenOptOut in an event's description stops watch from opening its
+// link.
+const autoOpenOptOut = "#no-auto-open"
+
+// parseOpenLink parses --open-link values, DURATION for every calendar or
+// CALENDAR=DURATION for one, into w.openLink. Calendar names are resolved
+// against entries.
+func (w *watcher) parseOpenLink(values []string, entries []*calendar.CalendarListEntry) error {
+	for _, v := range values {
+		cal, value, ok := strings.Cut(v, "=")
+		if !ok {
+			cal, value = "", v
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("%q must be a duration such as 2m, optionally after CALENDAR=", v)
+		}
+		if cal != "" {
+			if cal, err = resolveCalendar(entries, cal); err != nil {
+				return err
+			}
+			if entry := calendarEntry(cal); entry != nil {
+				cal = entry.Id
+			}
+		}
+		w.openLink[cal] = d
+	}
+	return nil
+}
+
+// openBefore returns how long before it starts item's link is opened, 0 for
+// never.
+func (w *watcher) openBefore(item *calendar.Event) time.Duration {
+	if strings.Contains(item.Description, autoOpenOptOut) {
+		return 0
+	}
+	id := eventCalendar(item)
+	if entry := calendarEntry(id); entry != nil {
+		id = entry.Id
+	}
+	if d, ok := w.openLink[id]; ok {
+		return d
+	}
+	return w.openLink[""]
+}
+
+// lookahead is how far ahead of now a meeting can need acting on.
+func (w *watcher) lookahead() time.Duration {
+	d := w.before
+	for _, open := range w.openLink {
+		d = max(d, open)
+	}
+	return d
+}
+
+// watchKey identifies one occurrence of a meeting. A moved meeting gets a
+// new key and so a new notification.
+func watchKey(item *calendar.Event, start time.Time) string {
+	return item.Id + "@" + start.Format(time.RFC3339)
+}
+
+// check fetches what starts before the next poll is due, notifies of the
+// meetings that are now within w.before of their start and opens the links
+// of those within openBefore. Hooks run as meetings start and end.
+func (w *watcher) check(ctx context.Context, now time.Time) error {
+	events, err := fetchEvents(ctx, w.srv, now, now.Add(w.lookahead()+w.interval))
+	if err != nil {
+		return err
+	}
+	for _, item := range events.Items {
+		start, end, ok := eventTimes(item)
+		if !ok || declinedBySelf(item) {
+			continue
+		}
+		key := watchKey(item, start)
+		if !start.After(now) {
+			if _, ok := w.started[key]; !ok && end.After(now) {
+				w.started[key] = item
+				w.runHooks(hookMeetingStart, item)
+			}
+			continue
+		}
+		if _, ok := w.notified[key]; !ok && start.Sub(now) <= w.before {
+			w.notified[key] = start
+			w.log.Info("notifying", "event", item.Id, "summary", item.Summary, "start", start)
+			if err := notify(meetingNotification(item, start, now)); err != nil {
+				w.log.Warn("unable to show notification", "error", err)
+			}
+		}
+		link := eventLink(item, *linkSource)
+		if _, ok := w.opened[key]; !ok && link != "" && start.Sub(now) <= w.openBefore(item) {
+			w.opened[key] = start
+			w.log.Info("opening link", "event", item.Id, "summary", item.Summary, "link", link)
+			if err := openBrowser(link); err != nil {
+				w.log.Warn("unable to open link", "link", link, "error", err)
+			}
+		}
+	}
+	for _, done := range []map[string]time.Time{w.notified, w.opened} {
+		for key, start := range done {
+			if start.Before(now) {
+				delete(done, key)
+			}
+		}
+	}
+	for key, item := range w.started {
+		if _, end, _ := eventTimes(item); !end.After(now) {
+			delete(w.started, key)
+			w.runHooks(hookMeetingEnd, item)
+		}
+	}
+	if w.slack != nil {
+		ongoing := make([]*calendar.Event, 0, len(w.started))
+		for _, item := range w.started {
+			ongoing = append(ongoing, item)
+		}
+		if err := w.slack.sync(ctx, ongoing); err != nil {
+			w.log.Warn("unable to set the Slack status", "error", err)
+		}
+	}
+	if len(w.hooks[hookNewInvite]) > 0 {
+		return w.checkInvites(ctx, now)
+	}
+	return nil
+}
+
+// checkInvites runs the on_new_invite hook for unanswered invitations that
+// arrived since the last check. The first check only notes the time, so
+// that starting up does not replay every pending invitation.
+func (w *watcher) checkInvites(ctx context.Context, now time.Time) error {
+	if w.invitesSince.IsZero() {
+		w.invitesSince = now
+		return nil
+	}
+	events, err := fetchUpdated(ctx, w.srv, w.invitesSince)
+	if err != nil {
+		return err
+	}
+	w.invitesSince = now
+	for _, item := range events.Items {
+		if !invitePending(item) || w.invited[item.Id] {
+			continue
+		}
+		if _, end, ok := eventTimes(item); ok && !end.After(now) {
+			continue
+		}
+		w.invited[item.Id] = true
+		w.runHooks(hookNewInvite, item)
+	}
+	return nil
+}
+
+// meetingNotification describes item, which starts at start. Clicking it
+// joins the meeting, or opens the event when there is nothing to join.
+func meetingNotification(item *calendar.Event, start, now time.Time) notification {
+	body := []string{fmt.Sprintf("Starts at %s (in %s)", start.Local().Format("15:04"), shortDuration(start.Sub(now)))}
+	if item.Location != "" {
+		body = append(body, item.Location)
+	}
+	url := eventLink(item, *linkSource)
+	if url == "" {
+		url = item.HtmlLink
+	}
+	return notification{Title: item.Summary, Body: strings.Join(body, "\n"), URL: url}
+}
+
+// run checks now and then every w.interval until ctx is done. Failed checks,
+// say while offline, are logged and retried at the next poll.
+func (w *watcher) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		checkCtx, cancel := requestContext()
+		now := time.Now()
+		err := w.check(checkCtx, now)
+		cancel()
+		if err != nil {
+			w.log.Warn("unable to check the calendar", "error", err)
+		}
+		w.mu.Lock()
+		w.lastCheck, w.calendars, w.lastErr = now, calendarIDs(), err
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			w.clearSlack()
+			return
+		case <-ticker.C:
+		case <-w.reloads:
+			if w.reload == nil {
+				continue
+			}
+			reloadCtx, cancel := requestContext()
+			if err := w.reload(reloadCtx); err != nil {
+				w.log.Error("unable to reload", "error", err)
+			} else {
+				w.log.Info("reloaded")
+			}
+			cancel()
+		}
+	}
+}
+
+// clearSlack clears the Slack status on the way out, if watch set it.
+func (w *watcher) clearSlack() {
+	if w.slack == nil {
+		return
+	}
+	ctx, cancel := requestContext()
+	defer cancel()
+	if err := w.slack.sync(ctx, nil); err != nil {
+		w.log.Warn("unable to clear the Slack status", "error", err)
+	}
+}
+
+// setupWatcher creates the watcher the watch flags describe.
+func setupWatcher(srv *calendar.Service) (*watcher, error) {
+	if watchBefore <= 0 || watchInterval <= 0 {
+		return nil, errors.New("--before and --interval must be positive")
+	}
+	w := newWatcher(srv, watchBefore, watchInterval)
+	s, err := loadSettings()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	if w.hooks, err = parseHooks(s); err != nil {
+		return nil, err
+	}
+	if watchSlackStatus {
+		tok, err := slackToken()
+		if err != nil {
+			return nil, fmt.Errorf("--slack-status: %w", err)
+		}
+		w.slack = &slackStatus{token: tok, client: &http.Client{Timeout: slackTimeout}}
+	}
+	if len(watchOpenLink) == 0 {
+		return w, nil
+	}
+	ctx, cancel := requestContext()
+	defer cancel()
+	entries, _, err := cachedCalendars(ctx, srv, false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list calendars: %w", err)
+	}
+	if calendarList == nil {
+		calendarList = entries
+	}
+	if err := w.parseOpenLink(watchOpenLink, entries); err != nil {
+		return nil, fmt.Errorf("--open-link: %w", err)
+	}
+	return w, nil
+}
+
+// The watch command's flags.
+var (
+	watchBefore   time.Duration
+	watchInterval time.Duration
+	watchOpenLink stringList
+
+	watchSlackStatus bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Run in the background and notify before each meeting",
+	Long: "Poll the selected calendars every --interval and show a desktop notification --before each meeting starts. " +
+		"Clicking the notification joins the meeting where the platform supports it.\n\n" +
+		"Notifications use notify-send on Linux, terminal-notifier or osascript on macOS and toasts on Windows. " +
+		"Declined invitations are skipped.\n\n" +
+		"With --open-link the meeting's link is also opened in the browser shortly before it starts, for every calendar " +
+		"or, as CALENDAR=DURATION, for one; 0 turns it off for a calendar. Events whose description contains " +
+		autoOpenOptOut + " are never opened.\n\n" +
+		"The hooks section of the config file sets commands to run as meetings start and end and as invitations " +
+		"arrive, with the event in $GCAL_EVENT_SUMMARY, $GCAL_EVENT_START, $GCAL_EVENT_LINK and similar variables.\n\n" +
+		"With --slack-status your Slack status reads \"In a meeting until 15:00\" during meetings; see \"slack login\".",
+	Example: `  go-gcal-cli watch --before 5m
+  go-gcal-cli watch --calendar work --before 2m --interval 30s
+  go-gcal-cli watch --calendar primary --calendar team --open-link 1m --open-link team=0
+  go-gcal-cli watch --slack-status`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		w, err := setupWatcher(newService())
+		if err != nil {
+			fatal("Unable to watch", err)
+		}
+		fmt.Fprintf(os.Stderr, "Watching for meetings %s ahead; press Ctrl+C to stop.\n", shortDuration(watchBefore))
+		w.run(context.Background())
+	},
+}
+
+// addWatchFlags registers the flags shared by watch and daemon.
+func addWatchFlags(f *pflag.FlagSet) {
+	f.DurationVar(&watchBefore, "before", 5*time.Minute, "how long before a meeting to notify")
+	f.DurationVar(&watchInterval, "interval", time.Minute, "how often to poll the calendar")
+	f.Var(&watchOpenLink, "open-link", "open the meeting link this long before it starts, as DURATION or CALENDAR=DURATION; repeatable")
+	f.BoolVar(&watchSlackStatus, "slack-status", false, "set the Slack status during meetings and clear it afterwards")
+}
+
+func init() {
+	addWatchFlags(watchCmd.Flags())
+	rootCmd.AddCommand(watchCmd)
+}